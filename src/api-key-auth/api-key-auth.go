@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"redis"
+)
+
+const PluginName = "api-key-auth"
+
+func main() {}
+
+type handlerRegisterer string
+
+var HandlerRegisterer = handlerRegisterer(PluginName)
+
+func (hr handlerRegisterer) RegisterHandlers(f func(
+	name string,
+	handler func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error),
+)) {
+	f(string(hr), hr.registerHandlers)
+}
+
+// registerHandlers wraps the gateway router. KrakenD wraps the handlers in
+// the order of the http-server names, so api-key-auth goes last to run
+// before the others. Redis settings live at the root of the plugin
+// configuration, next to the authentication settings.
+func (hr handlerRegisterer) registerHandlers(ctx context.Context, extra map[string]interface{}, next http.Handler) (http.Handler, error) {
+	cfg, _ := extra[PluginName].(map[string]interface{})
+
+	config, err := LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading %s config: %v", PluginName, err)
+	}
+
+	redisConfig, err := redis.LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading Redis config: %v", err)
+	}
+
+	// Keys are looked up lazily, so the gateway starts even while Redis is
+	// down and rejects requests with 503 until it comes back.
+	store := redis.NewLazyStore("API key", nil, redis.OpenUntilDone(ctx, redisConfig))
+	if _, err := store.Get(); err != nil {
+		log.Printf("[API-KEY-AUTH] Redis unavailable at start: %v", err)
+	}
+
+	log.Printf("[API-KEY-AUTH] Authenticating %s, tenant forwarded as %s", config.Header, config.TenantHeader)
+	return NewAuthenticator(config, store).Handler(next), nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"redis"
+	"tenancy"
+)
+
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusRevoked   = "revoked"
+
+	lookupTimeout = 2 * time.Second
+	maxCachedKeys = 10000
+)
+
+// APIKey is the record stored under apikey:v1:<sha256 of the key>. Only the
+// hash is stored so a Redis dump does not leak usable keys.
+type APIKey struct {
+	TenantID         string   `json:"tenant_id"`
+	AllowedEndpoints []string `json:"allowed_endpoints"`
+	Status           string   `json:"status"`
+}
+
+// Allows reports whether the key may call the gateway path. Patterns are
+// exact paths, "*" for every endpoint, or a prefix ending in "/*".
+func (k *APIKey) Allows(requestPath string) bool {
+	for _, pattern := range k.AllowedEndpoints {
+		if matchPath(pattern, requestPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyName returns the store key of a raw API key.
+func KeyName(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return redis.GetNamespace(redis.NamespaceAPIKey).Key(hex.EncodeToString(sum[:]))
+}
+
+type cachedKey struct {
+	key     *APIKey
+	expires time.Time
+}
+
+// Authenticator validates the API key header of every request and forwards
+// the tenant of the key to the endpoints, in the tenant header, and to the
+// handlers it wraps, in the request context.
+type Authenticator struct {
+	config *Config
+	now    func() time.Time
+	store  *redis.LazyStore
+
+	mu    sync.Mutex
+	cache map[string]cachedKey
+}
+
+// NewAuthenticator looks the API keys up in store.
+func NewAuthenticator(config *Config, store *redis.LazyStore) *Authenticator {
+	return &Authenticator{
+		config: config,
+		now:    time.Now,
+		store:  store,
+		cache:  make(map[string]cachedKey),
+	}
+}
+
+func (a *Authenticator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Clients must never choose their tenant.
+		req.Header.Del(a.config.TenantHeader)
+
+		if a.public(req.URL.Path) {
+			next.ServeHTTP(w, req.WithContext(tenancy.NewContext(req.Context(), tenancy.Identity{Public: true})))
+			return
+		}
+
+		apiKey := req.Header.Get(a.config.Header)
+		if apiKey == "" {
+			http.Error(w, "missing API key", http.StatusUnauthorized)
+			return
+		}
+
+		key, err := a.lookup(req.Context(), apiKey)
+		if err != nil {
+			log.Printf("[API-KEY-AUTH] %v", err)
+			http.Error(w, "authentication unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		switch {
+		case key == nil || key.Status == StatusRevoked:
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		case key.Status != StatusActive:
+			log.Printf("[API-KEY-AUTH] Rejected %s key of tenant %s", key.Status, key.TenantID)
+			http.Error(w, "API key is not active", http.StatusForbidden)
+			return
+		case !key.Allows(req.URL.Path):
+			log.Printf("[API-KEY-AUTH] Tenant %s is not allowed to call %s", key.TenantID, req.URL.Path)
+			http.Error(w, "endpoint not allowed for this API key", http.StatusForbidden)
+			return
+		}
+
+		// The key itself is not needed past the gateway.
+		req.Header.Del(a.config.Header)
+		req.Header.Set(a.config.TenantHeader, key.TenantID)
+		next.ServeHTTP(w, req.WithContext(tenancy.NewContext(req.Context(), tenancy.Identity{Tenant: key.TenantID})))
+	})
+}
+
+func (a *Authenticator) public(requestPath string) bool {
+	for _, pattern := range a.config.PublicPaths {
+		if matchPath(pattern, requestPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the record of apiKey, nil when it does not exist. Both
+// outcomes are cached for CacheTTL; store errors are not.
+func (a *Authenticator) lookup(ctx context.Context, apiKey string) (*APIKey, error) {
+	name := KeyName(apiKey)
+	now := a.now()
+
+	a.mu.Lock()
+	cached, exists := a.cache[name]
+	a.mu.Unlock()
+	if exists && now.Before(cached.expires) {
+		return cached.key, nil
+	}
+
+	store, err := a.store.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+	defer cancel()
+
+	var key APIKey
+	if err := store.GetJSON(ctx, name, &key); err != nil {
+		if !redis.IsNil(err) {
+			return nil, fmt.Errorf("error looking up API key: %v", err)
+		}
+		a.remember(name, nil, now)
+		return nil, nil
+	}
+
+	a.remember(name, &key, now)
+	return &key, nil
+}
+
+func (a *Authenticator) remember(name string, key *APIKey, now time.Time) {
+	if a.config.CacheTTL <= 0 {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.cache) >= maxCachedKeys {
+		// Unknown keys sent in bulk must not grow the cache without bound.
+		a.cache = make(map[string]cachedKey)
+	}
+	a.cache[name] = cachedKey{
+		key:     key,
+		expires: now.Add(time.Duration(a.config.CacheTTL) * time.Second),
+	}
+}
+
+func matchPath(pattern, requestPath string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/")
+	}
+	matched, err := path.Match(pattern, requestPath)
+	return err == nil && matched
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"redis"
+)
+
+func newTestAuthenticator(t *testing.T) (*Authenticator, *redis.MemoryStore) {
+	t.Helper()
+	store := redis.NewMemoryStore(0)
+	ctx := context.Background()
+	store.Set(ctx, KeyName("good"), `{"tenant_id":"acme","allowed_endpoints":["/hoteles/*","/carros/matrix"],"status":"active"}`)
+	store.Set(ctx, KeyName("paused"), `{"tenant_id":"acme","allowed_endpoints":["*"],"status":"suspended"}`)
+	return NewAuthenticator(DefaultConfig(), redis.NewLazyStore("API key", store, nil)), store
+}
+
+func serve(a *Authenticator, path, apiKey string, headers map[string]string) (*httptest.ResponseRecorder, *http.Request) {
+	var forwarded *http.Request
+	handler := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		forwarded = req
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, forwarded
+}
+
+func TestAuthenticator_ForwardsTenant(t *testing.T) {
+	a, _ := newTestAuthenticator(t)
+
+	rec, forwarded := serve(a, "/hoteles/search", "good", map[string]string{"X-Tenant-ID": "spoofed"})
+	if rec.Code != http.StatusOK || forwarded == nil {
+		t.Fatalf("expected request to be forwarded, got %d", rec.Code)
+	}
+	if tenant := forwarded.Header.Get("X-Tenant-ID"); tenant != "acme" {
+		t.Errorf("expected tenant acme, got %s", tenant)
+	}
+	if forwarded.Header.Get("X-API-Key") != "" {
+		t.Error("expected API key to be removed before forwarding")
+	}
+}
+
+func TestAuthenticator_Rejects(t *testing.T) {
+	a, _ := newTestAuthenticator(t)
+
+	for _, tc := range []struct {
+		path, key string
+		status    int
+	}{
+		{"/hoteles/search", "", http.StatusUnauthorized},
+		{"/hoteles/search", "unknown", http.StatusUnauthorized},
+		{"/hoteles/search", "paused", http.StatusForbidden},
+		{"/carros/reservas", "good", http.StatusForbidden},
+		{"/hotelesx", "good", http.StatusForbidden},
+	} {
+		rec, forwarded := serve(a, tc.path, tc.key, nil)
+		if rec.Code != tc.status || forwarded != nil {
+			t.Errorf("%s with key %q: expected %d, got %d", tc.path, tc.key, tc.status, rec.Code)
+		}
+	}
+}
+
+func TestAuthenticator_PublicPathsStripTenant(t *testing.T) {
+	a, _ := newTestAuthenticator(t)
+
+	rec, forwarded := serve(a, "/__health", "", map[string]string{"X-Tenant-ID": "spoofed"})
+	if rec.Code != http.StatusOK || forwarded == nil {
+		t.Fatalf("expected public path to be forwarded, got %d", rec.Code)
+	}
+	if forwarded.Header.Get("X-Tenant-ID") != "" {
+		t.Error("expected client tenant header to be removed")
+	}
+}
+
+func TestAuthenticator_CachesLookups(t *testing.T) {
+	a, store := newTestAuthenticator(t)
+
+	serve(a, "/hoteles/search", "good", nil)
+	store.Delete(context.Background(), KeyName("good"))
+
+	if rec, _ := serve(a, "/hoteles/search", "good", nil); rec.Code != http.StatusOK {
+		t.Errorf("expected cached key to be accepted, got %d", rec.Code)
+	}
+
+	a.config.CacheTTL = 0
+	a.cache = make(map[string]cachedKey)
+	if rec, _ := serve(a, "/hoteles/search", "good", nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected deleted key to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestAuthenticator_StoreDown(t *testing.T) {
+	a := NewAuthenticator(DefaultConfig(), redis.NewLazyStore("API key", nil, func() (redis.Store, error) {
+		return nil, errors.New("connection refused")
+	}))
+
+	if rec, forwarded := serve(a, "/hoteles/search", "good", nil); rec.Code != http.StatusServiceUnavailable || forwarded != nil {
+		t.Errorf("expected 503 while the store is down, got %d", rec.Code)
+	}
+}
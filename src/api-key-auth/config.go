@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type Config struct {
+	Header       string `json:"header"`
+	TenantHeader string `json:"tenant_header"`
+	// PublicPaths skip authentication, e.g. health checks. A trailing "/*"
+	// matches every path below.
+	PublicPaths []string `json:"public_paths"`
+	// CacheTTL keeps looked up keys in memory for this many seconds, so
+	// revocations take effect after at most CacheTTL. 0 disables it.
+	CacheTTL int `json:"cache_ttl"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Header:       "X-API-Key",
+		TenantHeader: "X-Tenant-ID",
+		PublicPaths:  []string{"/__health"},
+		CacheTTL:     30,
+	}
+}
+
+func LoadConfig(extra map[string]interface{}) (*Config, error) {
+	config := DefaultConfig()
+
+	configData, err := json.Marshal(extra)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling config: %v", err)
+	}
+
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling config: %v", err)
+	}
+
+	if config.Header == "" {
+		config.Header = "X-API-Key"
+	}
+	if config.TenantHeader == "" {
+		config.TenantHeader = "X-Tenant-ID"
+	}
+	config.Header = http.CanonicalHeaderKey(config.Header)
+	config.TenantHeader = http.CanonicalHeaderKey(config.TenantHeader)
+	if config.CacheTTL < 0 {
+		config.CacheTTL = 0
+	}
+
+	return config, nil
+}
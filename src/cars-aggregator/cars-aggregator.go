@@ -0,0 +1,33 @@
+package main
+
+import (
+	"cars-common/core"
+	"cars-common/transformers"
+	"fmt"
+)
+
+const PluginName = "cars-aggregator"
+
+var pluginCore *core.CarPluginCore
+
+func main() {}
+
+func init() {
+	pluginCore = core.NewCarPluginCore()
+	pluginCore.RegisterTransformer(transformers.NewRentlyTransformer())
+	pluginCore.RegisterTransformer(transformers.NewFoxTransformer())
+	fmt.Println(PluginName, "loaded!!!")
+}
+
+type registerer string
+
+var ModifierRegisterer = registerer(PluginName)
+
+func (r registerer) RegisterModifiers(f func(
+	name string,
+	modifierFactory func(map[string]interface{}) func(interface{}) (interface{}, error),
+	appliesToRequest bool,
+	appliesToResponse bool,
+)) {
+	f(string(r), pluginCore.CreateAggregationModifierFactory(), false, true)
+}
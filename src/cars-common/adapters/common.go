@@ -0,0 +1,149 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/url"
+	"reflect"
+
+	"cars-common/models"
+)
+
+type ResponseWrapper interface {
+	Data() map[string]interface{}
+	Io() io.Reader
+	IsComplete() bool
+	StatusCode() int
+	Headers() map[string][]string
+}
+
+type RequestWrapper interface {
+	Query() url.Values
+	Headers() map[string][]string
+}
+
+type ResponseExtractor struct{}
+
+func NewResponseExtractor() *ResponseExtractor {
+	return &ResponseExtractor{}
+}
+
+func (re *ResponseExtractor) ExtractData(input interface{}) (models.ResponseData, bool) {
+	if wrapper, ok := input.(ResponseWrapper); ok {
+		if data := wrapper.Data(); data != nil {
+			return models.ResponseData(data), true
+		}
+		return re.extractFromReader(wrapper.Io())
+	}
+
+	if mapData, ok := input.(map[string]interface{}); ok {
+		return models.ResponseData(mapData), true
+	}
+
+	if data := re.callDataMethod(input); data != nil {
+		return models.ResponseData(data), true
+	}
+
+	return nil, false
+}
+
+func (re *ResponseExtractor) extractFromReader(reader io.Reader) (models.ResponseData, bool) {
+	if reader == nil {
+		return nil, false
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(reader).Decode(&result); err != nil {
+		log.Printf("[CARS-EXTRACTOR] Error decoding body: %v", err)
+		return nil, false
+	}
+	return models.ResponseData(result), true
+}
+
+func (re *ResponseExtractor) callDataMethod(input interface{}) map[string]interface{} {
+	dataMethod := reflect.ValueOf(input).MethodByName("Data")
+	if dataMethod.IsValid() {
+		results := dataMethod.Call(nil)
+		if len(results) > 0 {
+			if dataMap, ok := results[0].Interface().(map[string]interface{}); ok {
+				return dataMap
+			}
+		}
+	}
+	return nil
+}
+
+// ExtractRequest returns the client request attached to a response wrapper,
+// if the gateway exposes it.
+func (re *ResponseExtractor) ExtractRequest(input interface{}) (RequestWrapper, bool) {
+	requestMethod := reflect.ValueOf(input).MethodByName("Request")
+	if !requestMethod.IsValid() || requestMethod.Type().NumIn() != 0 {
+		return nil, false
+	}
+	results := requestMethod.Call(nil)
+	if len(results) == 0 {
+		return nil, false
+	}
+	request, ok := results[0].Interface().(RequestWrapper)
+	return request, ok
+}
+
+// NewResponse wraps data so it can be returned from a response modifier,
+// keeping the status, headers and completeness of the original response.
+func NewResponse(data map[string]interface{}, original interface{}) ResponseWrapper {
+	body, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[CARS-ADAPTER] Error marshaling response: %v", err)
+	}
+
+	response := &modifiedResponseWrapper{
+		data:       data,
+		body:       body,
+		statusCode: 200,
+		isComplete: true,
+	}
+
+	if wrapper, ok := original.(ResponseWrapper); ok {
+		response.statusCode = wrapper.StatusCode()
+		response.headers = wrapper.Headers()
+		response.isComplete = wrapper.IsComplete()
+	}
+
+	return response
+}
+
+type modifiedResponseWrapper struct {
+	data       map[string]interface{}
+	body       []byte
+	statusCode int
+	headers    map[string][]string
+	isComplete bool
+}
+
+func (m *modifiedResponseWrapper) Data() map[string]interface{} {
+	if m.data == nil {
+		return map[string]interface{}{}
+	}
+	return m.data
+}
+
+func (m *modifiedResponseWrapper) Io() io.Reader {
+	return bytes.NewReader(m.body)
+}
+
+func (m *modifiedResponseWrapper) IsComplete() bool {
+	return m.isComplete
+}
+
+func (m *modifiedResponseWrapper) StatusCode() int {
+	return m.statusCode
+}
+
+func (m *modifiedResponseWrapper) Headers() map[string][]string {
+	if m.headers == nil {
+		return make(map[string][]string)
+	}
+	return m.headers
+}
@@ -0,0 +1,66 @@
+package aggregation
+
+import (
+	"sort"
+
+	"cars-common/models"
+	"cars-common/sipp"
+)
+
+const UnclassifiedClass = "unclassified"
+
+// Aggregate merges the offers of every supplier into one list grouped by
+// decoded vehicle class. Offers in a class are sorted by customer total and
+// the cheapest one is marked.
+func Aggregate(cars []models.Car) models.AggregatedResponse {
+	groups := make(map[string]*models.ClassGroup)
+	order := make([]string, 0)
+
+	for _, car := range cars {
+		class := car.Vehicle.Category
+		if class == "" {
+			class = UnclassifiedClass
+		}
+
+		group, exists := groups[class]
+		if !exists {
+			group = &models.ClassGroup{
+				Class:  class,
+				Offers: []models.Car{},
+			}
+			groups[class] = group
+			order = append(order, class)
+		}
+		group.Offers = append(group.Offers, car)
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return sipp.CategoryRank(order[i]) < sipp.CategoryRank(order[j])
+	})
+
+	response := models.AggregatedResponse{
+		Classes: make([]models.ClassGroup, 0, len(order)),
+	}
+
+	for _, class := range order {
+		group := groups[class]
+		sort.SliceStable(group.Offers, func(i, j int) bool {
+			if group.Offers[i].Rate.TotalPrice != group.Offers[j].Rate.TotalPrice {
+				return group.Offers[i].Rate.TotalPrice < group.Offers[j].Rate.TotalPrice
+			}
+			return group.Offers[i].Provider < group.Offers[j].Provider
+		})
+
+		if len(group.Offers) > 0 {
+			cheapest := &group.Offers[0]
+			cheapest.Cheapest = true
+			group.CheapestSupplier = cheapest.Provider
+			group.CheapestTotal = cheapest.Rate.TotalPrice
+			group.Currency = cheapest.Rate.Currency
+		}
+
+		response.Classes = append(response.Classes, *group)
+	}
+
+	return response
+}
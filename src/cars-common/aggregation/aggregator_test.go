@@ -0,0 +1,78 @@
+package aggregation
+
+import (
+	"net/url"
+	"testing"
+
+	"cars-common/models"
+	"cars-common/sipp"
+)
+
+func car(provider models.Provider, code string, total float64) models.Car {
+	return models.Car{
+		Provider: provider,
+		Vehicle:  sipp.DecodeOrEmpty(code),
+		Rate:     models.Rate{TotalPrice: total, Currency: "USD"},
+	}
+}
+
+func TestAggregate_GroupsAndMarksCheapest(t *testing.T) {
+	response := Aggregate([]models.Car{
+		car(models.ProviderRently, "SFAR", 300),
+		car(models.ProviderFox, "ECMR", 120),
+		car(models.ProviderRently, "EDAR", 110),
+		car(models.ProviderFox, "SFAD", 280),
+	})
+
+	if len(response.Classes) != 2 {
+		t.Fatalf("expected 2 classes, got %d", len(response.Classes))
+	}
+
+	economy := response.Classes[0]
+	if economy.Class != "economy" {
+		t.Errorf("expected economy first, got %s", economy.Class)
+	}
+	if economy.CheapestSupplier != models.ProviderRently || economy.CheapestTotal != 110 {
+		t.Errorf("expected Rently at 110, got %s at %.2f", economy.CheapestSupplier, economy.CheapestTotal)
+	}
+	if !economy.Offers[0].Cheapest || economy.Offers[1].Cheapest {
+		t.Errorf("expected only the first offer to be marked cheapest")
+	}
+
+	standard := response.Classes[1]
+	if standard.CheapestSupplier != models.ProviderFox || standard.Offers[1].Rate.TotalPrice != 300 {
+		t.Errorf("expected Fox cheapest and offers sorted by total, got %+v", standard)
+	}
+}
+
+func TestAggregate_Unclassified(t *testing.T) {
+	response := Aggregate([]models.Car{
+		car(models.ProviderRently, "", 90),
+		car(models.ProviderFox, "MBMN", 80),
+	})
+
+	if len(response.Classes) != 2 {
+		t.Fatalf("expected 2 classes, got %d", len(response.Classes))
+	}
+	if response.Classes[1].Class != UnclassifiedClass {
+		t.Errorf("expected unclassified last, got %s", response.Classes[1].Class)
+	}
+}
+
+func TestParseFilter_Apply(t *testing.T) {
+	oneWay := car(models.ProviderRently, "ECAR", 100)
+	oneWay.OneWay = true
+	oneWay.PickupLocationType = models.LocationTypeAirport
+	roundTrip := car(models.ProviderFox, "ECMR", 90)
+
+	filter := ParseFilter(url.Values{"one_way": {"true"}, "pickup_location_type": {"Airport"}})
+	filtered := filter.Apply([]models.Car{oneWay, roundTrip})
+	if len(filtered) != 1 || filtered[0].Provider != models.ProviderRently {
+		t.Errorf("expected only the one-way airport offer, got %+v", filtered)
+	}
+
+	filtered = ParseFilter(url.Values{"transmission": {"manual"}}).Apply([]models.Car{oneWay, roundTrip})
+	if len(filtered) != 1 || filtered[0].Provider != models.ProviderFox {
+		t.Errorf("expected only the manual offer, got %+v", filtered)
+	}
+}
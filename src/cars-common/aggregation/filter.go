@@ -0,0 +1,56 @@
+package aggregation
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"cars-common/models"
+)
+
+// Filter narrows the aggregated offers with the query string of the client
+// request. Empty fields match every offer.
+type Filter struct {
+	OneWay             *bool
+	PickupLocationType string
+	Transmission       string
+	Category           string
+}
+
+func ParseFilter(query url.Values) Filter {
+	filter := Filter{
+		PickupLocationType: strings.ToLower(query.Get("pickup_location_type")),
+		Transmission:       strings.ToLower(query.Get("transmission")),
+		Category:           strings.ToLower(query.Get("category")),
+	}
+	if oneWay, err := strconv.ParseBool(query.Get("one_way")); err == nil {
+		filter.OneWay = &oneWay
+	}
+	return filter
+}
+
+func (f Filter) Matches(car models.Car) bool {
+	if f.OneWay != nil && car.OneWay != *f.OneWay {
+		return false
+	}
+	if f.PickupLocationType != "" && car.PickupLocationType != f.PickupLocationType {
+		return false
+	}
+	if f.Transmission != "" && car.Vehicle.Transmission != f.Transmission {
+		return false
+	}
+	if f.Category != "" && car.Vehicle.Category != f.Category {
+		return false
+	}
+	return true
+}
+
+func (f Filter) Apply(cars []models.Car) []models.Car {
+	filtered := make([]models.Car, 0, len(cars))
+	for _, car := range cars {
+		if f.Matches(car) {
+			filtered = append(filtered, car)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"cars-common/adapters"
+	"cars-common/aggregation"
+	"cars-common/models"
+	"cars-common/rendering"
+	"cars-common/transformers"
+)
+
+type CarPluginCore struct {
+	registry  *transformers.TransformerRegistry
+	bookings  map[models.Provider]transformers.BookingTransformer
+	extractor *adapters.ResponseExtractor
+}
+
+func NewCarPluginCore() *CarPluginCore {
+	return &CarPluginCore{
+		registry:  transformers.NewTransformerRegistry(),
+		bookings:  make(map[models.Provider]transformers.BookingTransformer),
+		extractor: adapters.NewResponseExtractor(),
+	}
+}
+
+func (cpc *CarPluginCore) RegisterTransformer(transformer transformers.CarTransformer) {
+	cpc.registry.Register(transformer)
+}
+
+func (cpc *CarPluginCore) RegisterBookingTransformer(transformer transformers.BookingTransformer) {
+	cpc.bookings[transformer.GetProvider()] = transformer
+}
+
+// CollectCars runs the matching transformer over every backend group of a
+// merged response. groups maps a group name to its provider; when it is empty
+// every object at the root is offered to the registered transformers.
+func (cpc *CarPluginCore) CollectCars(data models.ResponseData, groups map[string]models.Provider, config models.TransformationConfig) ([]models.Car, error) {
+	cars := make([]models.Car, 0)
+
+	for group, value := range data {
+		groupData, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var transformer transformers.CarTransformer
+		var found bool
+
+		if provider, exists := groups[group]; exists {
+			transformer, found = cpc.registry.Get(provider)
+		} else if len(groups) == 0 {
+			transformer, found = cpc.registry.FindByData(groupData)
+		}
+
+		if !found {
+			log.Printf("[CARS-CORE] No transformer found for group %s, skipping", group)
+			continue
+		}
+
+		response, err := transformer.Transform(groupData, config)
+		if err != nil {
+			return nil, fmt.Errorf("error transforming group %s: %w", group, err)
+		}
+		cars = append(cars, response.Cars...)
+	}
+
+	return cars, nil
+}
+
+func (cpc *CarPluginCore) Aggregate(input interface{}, groups map[string]models.Provider, config models.TransformationConfig, renderer *rendering.Renderer) (interface{}, error) {
+	data, success := cpc.extractor.ExtractData(input)
+	if !success {
+		log.Printf("[CARS-CORE] Could not extract data, returning original input")
+		return input, nil
+	}
+
+	cars, err := cpc.CollectCars(data, groups, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if request, ok := cpc.extractor.ExtractRequest(input); ok {
+		cars = aggregation.ParseFilter(request.Query()).Apply(cars)
+	}
+	profile := cpc.selectProfile(input, renderer)
+
+	aggregated := aggregation.Aggregate(cars)
+	log.Printf("[CARS-CORE] Aggregated %d cars into %d classes (profile %s)", len(cars), len(aggregated.Classes), profile)
+
+	return adapters.NewResponse(toMap(renderer.RenderClasses(aggregated, profile)), input), nil
+}
+
+func (cpc *CarPluginCore) CreateAggregationModifierFactory() func(map[string]interface{}) func(interface{}) (interface{}, error) {
+	return func(cfg map[string]interface{}) func(interface{}) (interface{}, error) {
+		config := cpc.extractConfig(cfg)
+		groups := extractGroups(cfg)
+		outputProfile, _ := cfg["output_profile"].(string)
+		renderer := rendering.NewRenderer(outputProfile)
+
+		log.Printf("[CARS-CORE] Aggregation initialized with groups=%v, output_profile=%s", groups, outputProfile)
+
+		return func(input interface{}) (interface{}, error) {
+			return cpc.Aggregate(input, groups, config, renderer)
+		}
+	}
+}
+
+func (cpc *CarPluginCore) ProcessBooking(input interface{}, config models.TransformationConfig, renderer *rendering.Renderer) (interface{}, error) {
+	data, success := cpc.extractor.ExtractData(input)
+	if !success {
+		log.Printf("[CARS-CORE] Could not extract booking data, returning original input")
+		return input, nil
+	}
+
+	transformer, found := cpc.bookings[config.Provider]
+	if !found || !transformer.CanTransform(data) {
+		log.Printf("[CARS-CORE] No booking transformer applies to the %s response, returning original input", config.Provider)
+		return input, nil
+	}
+
+	profile := cpc.selectProfile(input, renderer)
+	booking := transformer.Transform(data)
+
+	return adapters.NewResponse(toMap(renderer.RenderBooking(booking, profile)), input), nil
+}
+
+func (cpc *CarPluginCore) CreateBookingModifierFactory(defaultProvider models.Provider) func(map[string]interface{}) func(interface{}) (interface{}, error) {
+	return func(cfg map[string]interface{}) func(interface{}) (interface{}, error) {
+		config := cpc.extractConfig(cfg)
+		if config.Provider == "" {
+			config.Provider = defaultProvider
+		}
+		outputProfile, _ := cfg["output_profile"].(string)
+		renderer := rendering.NewRenderer(outputProfile)
+
+		log.Printf("[CARS-CORE] Booking modifier initialized with provider=%s, output_profile=%s", config.Provider, outputProfile)
+
+		return func(input interface{}) (interface{}, error) {
+			return cpc.ProcessBooking(input, config, renderer)
+		}
+	}
+}
+
+func (cpc *CarPluginCore) selectProfile(input interface{}, renderer *rendering.Renderer) string {
+	if request, ok := cpc.extractor.ExtractRequest(input); ok {
+		return renderer.Select(request.Headers())
+	}
+	return renderer.Select(nil)
+}
+
+func (cpc *CarPluginCore) extractConfig(cfg map[string]interface{}) models.TransformationConfig {
+	config := models.TransformationConfig{
+		ExtraConfig: make(map[string]interface{}),
+	}
+
+	if provider, ok := cfg["provider"].(string); ok {
+		config.Provider = models.Provider(provider)
+	}
+
+	for key, value := range cfg {
+		if key != "provider" {
+			config.ExtraConfig[key] = value
+		}
+	}
+
+	return config
+}
+
+func extractGroups(cfg map[string]interface{}) map[string]models.Provider {
+	groups := make(map[string]models.Provider)
+	if rawGroups, ok := cfg["groups"].(map[string]interface{}); ok {
+		for group, provider := range rawGroups {
+			if providerStr, ok := provider.(string); ok {
+				groups[group] = models.Provider(providerStr)
+			}
+		}
+	}
+	return groups
+}
+
+func toMap(value interface{}) map[string]interface{} {
+	var result map[string]interface{}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return result
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		log.Printf("[CARS-CORE] Error converting response: %v", err)
+	}
+	return result
+}
@@ -0,0 +1,3 @@
+module cars-common
+
+go 1.25.3
@@ -0,0 +1,158 @@
+package models
+
+type Provider string
+
+const (
+	ProviderRently Provider = "Rently"
+	ProviderFox    Provider = "Fox"
+)
+
+const (
+	LocationTypeAirport  = "airport"
+	LocationTypeDowntown = "downtown"
+
+	FeeTypeOneWay  = "one_way"
+	FeeTypeAirport = "airport"
+)
+
+type VehicleAttributes struct {
+	Code            string `json:"code"`
+	Category        string `json:"category"`
+	BodyType        string `json:"bodyType"`
+	Transmission    string `json:"transmission"`
+	Drive           string `json:"drive"`
+	Fuel            string `json:"fuel"`
+	AirConditioning bool   `json:"airConditioning"`
+}
+
+type Car struct {
+	Provider  Provider          `json:"provider"`
+	Supplier  string            `json:"supplier"`
+	Name      string            `json:"name"`
+	Brand     string            `json:"brand"`
+	Category  string            `json:"category"`
+	ImageURL  string            `json:"imageUrl"`
+	RateCode  string            `json:"rateCode"`
+	Days      int               `json:"days"`
+	Franchise float64           `json:"franchise"`
+	Vehicle   VehicleAttributes `json:"vehicle"`
+	Rate      Rate              `json:"rate"`
+	Cheapest  bool              `json:"cheapest"`
+
+	OneWay             bool   `json:"oneWay"`
+	PickupLocationType string `json:"pickupLocationType"`
+	ReturnLocationType string `json:"returnLocationType"`
+	Fees               []Fee  `json:"fees"`
+}
+
+type Fee struct {
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+type Rate struct {
+	Price      float64 `json:"price"`
+	BasePrice  float64 `json:"basePrice"`
+	Taxes      float64 `json:"taxes"`
+	TotalPrice float64 `json:"totalPrice"`
+	Currency   string  `json:"currency"`
+}
+
+type StandardResponse struct {
+	Pickup  *Location `json:"pickup"`
+	Dropoff *Location `json:"dropoff"`
+	Cars    []Car     `json:"cars"`
+}
+
+type Location struct {
+	Code         string  `json:"code"`
+	ID           float64 `json:"id"`
+	Email        string  `json:"email"`
+	City         string  `json:"city"`
+	Country      string  `json:"country"`
+	Address2     string  `json:"address2"`
+	ZipCode      string  `json:"zipCode"`
+	Type         string  `json:"type"`
+	ServiceType  string  `json:"serviceType"`
+	Instructions string  `json:"instructions"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	Date         string  `json:"date"`
+	Time         string  `json:"time"`
+	// HasPlace is false when the provider sent no place, only a date.
+	HasPlace bool `json:"-"`
+}
+
+type ClassGroup struct {
+	Class            string   `json:"class"`
+	CheapestSupplier Provider `json:"cheapestSupplier"`
+	CheapestTotal    float64  `json:"cheapestTotal"`
+	Currency         string   `json:"currency"`
+	Offers           []Car    `json:"offers"`
+}
+
+type AggregatedResponse struct {
+	Classes []ClassGroup `json:"classes"`
+}
+
+type TransformationConfig struct {
+	Provider    Provider               `json:"provider"`
+	ExtraConfig map[string]interface{} `json:"extraConfig,omitempty"`
+}
+
+type ResponseData map[string]interface{}
+
+type BookingOperation string
+
+const (
+	BookingCreate BookingOperation = "create"
+	BookingGet    BookingOperation = "get"
+	BookingCancel BookingOperation = "cancel"
+)
+
+type Driver struct {
+	FirstName      string `json:"firstName"`
+	LastName       string `json:"lastName"`
+	Email          string `json:"email"`
+	Phone          string `json:"phone"`
+	DocumentType   string `json:"documentType"`
+	DocumentNumber string `json:"documentNumber"`
+	Country        string `json:"country"`
+	BirthDate      string `json:"birthDate"`
+}
+
+type Extra struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+// BookingRequest is the body clients send to create a car reservation.
+type BookingRequest struct {
+	ModelID         string  `json:"modelId"`
+	RateCode        string  `json:"rateCode"`
+	PickupPlaceID   string  `json:"pickupPlaceId"`
+	DropoffPlaceID  string  `json:"dropoffPlaceId"`
+	PickupDateTime  string  `json:"pickupDateTime"`
+	DropoffDateTime string  `json:"dropoffDateTime"`
+	Driver          Driver  `json:"driver"`
+	Extras          []Extra `json:"extras"`
+	Comments        string  `json:"comments"`
+}
+
+type CancellationRequest struct {
+	Reason string `json:"reason"`
+}
+
+type Booking struct {
+	Provider           Provider  `json:"provider"`
+	ConfirmationNumber string    `json:"confirmationNumber"`
+	Status             string    `json:"status"`
+	Pickup             *Location `json:"pickup"`
+	Dropoff            *Location `json:"dropoff"`
+	Car                Car       `json:"car"`
+	Driver             Driver    `json:"driver"`
+	Extras             []Extra   `json:"extras"`
+}
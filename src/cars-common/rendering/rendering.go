@@ -0,0 +1,265 @@
+package rendering
+
+import (
+	"net/http"
+	"strconv"
+
+	"cars-common/models"
+)
+
+const (
+	ProfileV1ES = "v1-es"
+	ProfileV2EN = "v2-en"
+
+	AcceptVersionHeader = "Accept-Version"
+)
+
+// Profile turns the canonical car model into the JSON shape a group of
+// consumers expects.
+type Profile interface {
+	RenderCars(response models.StandardResponse) map[string]interface{}
+	RenderClasses(response models.AggregatedResponse) map[string]interface{}
+	RenderBooking(booking models.Booking) map[string]interface{}
+}
+
+type Renderer struct {
+	profiles       map[string]Profile
+	defaultProfile string
+}
+
+// NewRenderer registers the built-in profiles. An unknown defaultProfile
+// falls back to v2-en.
+func NewRenderer(defaultProfile string) *Renderer {
+	r := &Renderer{
+		profiles: map[string]Profile{
+			ProfileV1ES: v1ESProfile{},
+			ProfileV2EN: v2ENProfile{},
+		},
+		defaultProfile: ProfileV2EN,
+	}
+	if _, exists := r.profiles[defaultProfile]; exists {
+		r.defaultProfile = defaultProfile
+	}
+	return r
+}
+
+// Select picks the profile requested through the Accept-Version header,
+// falling back to the configured default when it is missing or unknown.
+func (r *Renderer) Select(headers map[string][]string) string {
+	requested := http.Header(headers).Get(AcceptVersionHeader)
+	if _, exists := r.profiles[requested]; exists {
+		return requested
+	}
+	return r.defaultProfile
+}
+
+func (r *Renderer) RenderCars(response models.StandardResponse, profile string) map[string]interface{} {
+	return r.profile(profile).RenderCars(response)
+}
+
+func (r *Renderer) RenderClasses(response models.AggregatedResponse, profile string) map[string]interface{} {
+	return r.profile(profile).RenderClasses(response)
+}
+
+func (r *Renderer) RenderBooking(booking models.Booking, profile string) map[string]interface{} {
+	return r.profile(profile).RenderBooking(booking)
+}
+
+func (r *Renderer) profile(name string) Profile {
+	if profile, exists := r.profiles[name]; exists {
+		return profile
+	}
+	return r.profiles[r.defaultProfile]
+}
+
+// v2ENProfile is the unified English schema shared with the hotel plugins.
+type v2ENProfile struct{}
+
+func (v2ENProfile) RenderCars(response models.StandardResponse) map[string]interface{} {
+	return map[string]interface{}{
+		"version": ProfileV2EN,
+		"pickup":  response.Pickup,
+		"dropoff": response.Dropoff,
+		"results": response.Cars,
+	}
+}
+
+func (v2ENProfile) RenderClasses(response models.AggregatedResponse) map[string]interface{} {
+	return map[string]interface{}{
+		"version": ProfileV2EN,
+		"classes": response.Classes,
+	}
+}
+
+func (v2ENProfile) RenderBooking(booking models.Booking) map[string]interface{} {
+	return map[string]interface{}{
+		"version": ProfileV2EN,
+		"booking": booking,
+	}
+}
+
+// v1ESProfile keeps the Spanish keys transform-rently has always returned.
+type v1ESProfile struct{}
+
+func (p v1ESProfile) RenderCars(response models.StandardResponse) map[string]interface{} {
+	// A response without cars keeps rendering "results": null.
+	var results []map[string]interface{}
+	for _, car := range response.Cars {
+		results = append(results, p.car(car))
+	}
+
+	return map[string]interface{}{
+		"pickup":  withLegacyDefaults(p.location(response.Pickup), "2025-11-21"),
+		"dropoff": withLegacyDefaults(p.location(response.Dropoff), "2025-11-26"),
+		"results": results,
+	}
+}
+
+func (p v1ESProfile) RenderClasses(response models.AggregatedResponse) map[string]interface{} {
+	classes := make([]map[string]interface{}, 0, len(response.Classes))
+	for _, class := range response.Classes {
+		offers := make([]map[string]interface{}, 0, len(class.Offers))
+		for _, car := range class.Offers {
+			offers = append(offers, p.car(car))
+		}
+		classes = append(classes, map[string]interface{}{
+			"clase":                class.Class,
+			"proveedor_mas_barato": class.CheapestSupplier,
+			"total_mas_barato":     class.CheapestTotal,
+			"moneda":               class.Currency,
+			"ofertas":              offers,
+		})
+	}
+
+	return map[string]interface{}{
+		"clases": classes,
+	}
+}
+
+func (p v1ESProfile) RenderBooking(booking models.Booking) map[string]interface{} {
+	extras := make([]map[string]interface{}, 0, len(booking.Extras))
+	for _, extra := range booking.Extras {
+		extras = append(extras, map[string]interface{}{
+			"id":       extra.ID,
+			"nombre":   extra.Name,
+			"cantidad": extra.Quantity,
+			"precio":   extra.Price,
+		})
+	}
+
+	return map[string]interface{}{
+		"reserva": map[string]interface{}{
+			"numero_confirmacion": booking.ConfirmationNumber,
+			"estado":              booking.Status,
+			"proveedor":           booking.Provider,
+			"pickup":              p.location(booking.Pickup),
+			"dropoff":             p.location(booking.Dropoff),
+			"vehiculo":            p.car(booking.Car),
+			"conductor": map[string]interface{}{
+				"nombre":           booking.Driver.FirstName,
+				"apellido":         booking.Driver.LastName,
+				"email":            booking.Driver.Email,
+				"telefono":         booking.Driver.Phone,
+				"tipo_documento":   booking.Driver.DocumentType,
+				"numero_documento": booking.Driver.DocumentNumber,
+				"pais":             booking.Driver.Country,
+				"fecha_nacimiento": booking.Driver.BirthDate,
+			},
+			"adicionales": extras,
+		},
+	}
+}
+
+func (p v1ESProfile) car(car models.Car) map[string]interface{} {
+	days := ""
+	if car.Days > 0 {
+		days = strconv.Itoa(car.Days)
+	}
+
+	surcharges := []map[string]interface{}{}
+	for _, fee := range car.Fees {
+		surcharges = append(surcharges, map[string]interface{}{
+			"tipo":        fee.Type,
+			"descripcion": fee.Description,
+			"monto":       fee.Amount,
+		})
+	}
+
+	return map[string]interface{}{
+		"nombre":               car.Name,
+		"marca":                car.Brand,
+		"tipo":                 car.Category,
+		"tarifa":               car.Rate.Price,
+		"tarifa_con_impuestos": car.Rate.TotalPrice,
+		"tarifa_sin_impuestos": car.Rate.BasePrice,
+		"proveedor":            car.Supplier,
+		"franquicia":           car.Franchise,
+		"dias_totales":         days,
+		"vehiculo": map[string]interface{}{
+			"codigo_sipp":        car.Vehicle.Code,
+			"categoria":          car.Vehicle.Category,
+			"carroceria":         car.Vehicle.BodyType,
+			"transmision":        car.Vehicle.Transmission,
+			"traccion":           car.Vehicle.Drive,
+			"combustible":        car.Vehicle.Fuel,
+			"aire_acondicionado": car.Vehicle.AirConditioning,
+		},
+		"solo_ida":       car.OneWay,
+		"tipo_ubicacion": car.PickupLocationType,
+		"recargos":       surcharges,
+	}
+}
+
+// location only emits the fields v1 has always emitted: the place details
+// when the provider sent a place, the code when it is known and the date
+// and time when they could be read.
+func (p v1ESProfile) location(location *models.Location) map[string]interface{} {
+	if location == nil {
+		return nil
+	}
+
+	info := make(map[string]interface{})
+	if location.Code != "" {
+		info["location"] = location.Code
+		info["iata"] = location.Code
+	}
+	if location.HasPlace {
+		info["id"] = location.ID
+		info["email"] = location.Email
+		info["city"] = location.City
+		info["country"] = location.Country
+		info["address2"] = location.Address2
+		info["zipCode"] = location.ZipCode
+		info["type"] = location.Type
+		info["serviceType"] = location.ServiceType
+		info["pickupInstructions"] = location.Instructions
+		info["latitude"] = location.Latitude
+		info["longitude"] = location.Longitude
+	}
+	if location.Date != "" {
+		info["date"] = location.Date
+	}
+	if location.Time != "" {
+		info["time"] = location.Time
+	}
+	return info
+}
+
+// withLegacyDefaults keeps the v1 availability defaults for responses without
+// a place or date.
+func withLegacyDefaults(info map[string]interface{}, defaultDate string) map[string]interface{} {
+	if info == nil {
+		return nil
+	}
+	if info["iata"] == nil {
+		info["location"] = "MIA"
+		info["iata"] = "MIA"
+	}
+	if info["date"] == nil {
+		info["date"] = defaultDate
+	}
+	if info["time"] == nil {
+		info["time"] = "1200"
+	}
+	return info
+}
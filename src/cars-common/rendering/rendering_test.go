@@ -0,0 +1,78 @@
+package rendering
+
+import (
+	"testing"
+
+	"cars-common/models"
+)
+
+func TestRenderer_Select(t *testing.T) {
+	renderer := NewRenderer(ProfileV1ES)
+
+	if profile := renderer.Select(nil); profile != ProfileV1ES {
+		t.Errorf("expected default v1-es, got %s", profile)
+	}
+	if profile := renderer.Select(map[string][]string{"Accept-Version": {"v2-en"}}); profile != ProfileV2EN {
+		t.Errorf("expected v2-en from header, got %s", profile)
+	}
+	if profile := renderer.Select(map[string][]string{"Accept-Version": {"v9-xx"}}); profile != ProfileV1ES {
+		t.Errorf("expected unknown version to fall back to v1-es, got %s", profile)
+	}
+	if profile := NewRenderer("unknown").Select(nil); profile != ProfileV2EN {
+		t.Errorf("expected unknown default to fall back to v2-en, got %s", profile)
+	}
+}
+
+func TestRenderer_RenderCars(t *testing.T) {
+	renderer := NewRenderer(ProfileV1ES)
+	response := models.StandardResponse{
+		Pickup: &models.Location{Code: "EZE", Date: "2025-11-29", Time: "1100"},
+		Cars: []models.Car{
+			{Name: "Onix", Days: 3, Rate: models.Rate{Price: 90, BasePrice: 100, TotalPrice: 150}},
+		},
+	}
+
+	v1 := renderer.RenderCars(response, ProfileV1ES)
+	results, ok := v1["results"].([]map[string]interface{})
+	if !ok || len(results) != 1 {
+		t.Fatalf("expected 1 v1 result, got %v", v1["results"])
+	}
+	if results[0]["tarifa_con_impuestos"] != 150.0 || results[0]["dias_totales"] != "3" {
+		t.Errorf("unexpected v1 car: %v", results[0])
+	}
+	if dropoff := v1["dropoff"].(map[string]interface{}); dropoff != nil {
+		t.Errorf("expected nil dropoff, got %v", dropoff)
+	}
+	if pickup := v1["pickup"].(map[string]interface{}); pickup["iata"] != "EZE" {
+		t.Errorf("expected pickup EZE, got %v", pickup["iata"])
+	}
+
+	v2 := renderer.RenderCars(response, ProfileV2EN)
+	if v2["version"] != ProfileV2EN {
+		t.Errorf("expected version v2-en, got %v", v2["version"])
+	}
+	if cars, ok := v2["results"].([]models.Car); !ok || cars[0].Name != "Onix" {
+		t.Errorf("expected canonical cars in v2 results, got %v", v2["results"])
+	}
+}
+
+func TestRenderer_V1LocationKeepsLegacyShape(t *testing.T) {
+	renderer := NewRenderer(ProfileV1ES)
+
+	v1 := renderer.RenderCars(models.StandardResponse{
+		Pickup:  &models.Location{Date: "2025-11-29", Time: "1100"},
+		Dropoff: &models.Location{Code: "EZE", City: "Buenos Aires", HasPlace: true},
+	}, ProfileV1ES)
+
+	pickup := v1["pickup"].(map[string]interface{})
+	if len(pickup) != 4 || pickup["iata"] != "MIA" || pickup["date"] != "2025-11-29" {
+		t.Errorf("expected only the code defaults, date and time without a place, got %v", pickup)
+	}
+	dropoff := v1["dropoff"].(map[string]interface{})
+	if dropoff["city"] != "Buenos Aires" || dropoff["date"] != "2025-11-26" || dropoff["time"] != "1200" {
+		t.Errorf("expected the place details with the legacy date defaults, got %v", dropoff)
+	}
+	if results := v1["results"].([]map[string]interface{}); results != nil {
+		t.Errorf("expected null results without cars, got %v", results)
+	}
+}
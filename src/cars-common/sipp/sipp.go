@@ -0,0 +1,164 @@
+package sipp
+
+import (
+	"fmt"
+	"strings"
+
+	"cars-common/models"
+)
+
+const (
+	TransmissionManual    = "manual"
+	TransmissionAutomatic = "automatic"
+
+	DriveUnspecified = "unspecified"
+	DriveFourWheel   = "4wd"
+	DriveAllWheel    = "awd"
+)
+
+var categories = map[byte]string{
+	'M': "mini",
+	'N': "mini_elite",
+	'E': "economy",
+	'H': "economy_elite",
+	'C': "compact",
+	'D': "compact_elite",
+	'I': "intermediate",
+	'J': "intermediate_elite",
+	'S': "standard",
+	'R': "standard_elite",
+	'F': "fullsize",
+	'G': "fullsize_elite",
+	'P': "premium",
+	'U': "premium_elite",
+	'L': "luxury",
+	'W': "luxury_elite",
+	'O': "oversize",
+	'X': "special",
+}
+
+// categoryOrder lists the categories from smallest to largest, the order the
+// rate matrix shows them in.
+var categoryOrder = []byte("MNEHCDIJSRFGPULWOX")
+
+var bodyTypes = map[byte]string{
+	'B': "2_3_door",
+	'C': "2_4_door",
+	'D': "4_5_door",
+	'W': "wagon",
+	'V': "passenger_van",
+	'L': "limousine",
+	'S': "sport",
+	'T': "convertible",
+	'F': "suv",
+	'J': "open_air_all_terrain",
+	'X': "special",
+	'P': "pickup_regular_cab",
+	'Q': "pickup_double_cab",
+	'Z': "special_offer",
+	'E': "coupe",
+	'M': "monospace",
+	'R': "recreational_vehicle",
+	'H': "motor_home",
+	'Y': "two_wheel_vehicle",
+	'N': "roadster",
+	'G': "crossover",
+	'K': "commercial_van",
+}
+
+type driveTrain struct {
+	transmission string
+	drive        string
+}
+
+var driveTrains = map[byte]driveTrain{
+	'M': {TransmissionManual, DriveUnspecified},
+	'N': {TransmissionManual, DriveFourWheel},
+	'C': {TransmissionManual, DriveAllWheel},
+	'A': {TransmissionAutomatic, DriveUnspecified},
+	'B': {TransmissionAutomatic, DriveFourWheel},
+	'D': {TransmissionAutomatic, DriveAllWheel},
+}
+
+type fuelAir struct {
+	fuel string
+	air  bool
+}
+
+var fuelAirs = map[byte]fuelAir{
+	'R': {"unspecified", true},
+	'N': {"unspecified", false},
+	'D': {"diesel", true},
+	'Q': {"diesel", false},
+	'H': {"hybrid", true},
+	'I': {"hybrid", true}, // plug-in
+	'E': {"electric", true},
+	'C': {"electric", true}, // long range
+	'L': {"lpg", true},
+	'S': {"lpg", false},
+	'A': {"hydrogen", true},
+	'B': {"hydrogen", false},
+	'M': {"multi_fuel", true},
+	'F': {"multi_fuel", false},
+	'V': {"petrol", true},
+	'Z': {"petrol", false},
+	'U': {"ethanol", true},
+	'X': {"ethanol", false},
+}
+
+// Decode turns a four-letter SIPP/ACRISS code (e.g. "ECMR") into normalized
+// vehicle attributes.
+func Decode(code string) (models.VehicleAttributes, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 4 {
+		return models.VehicleAttributes{}, fmt.Errorf("invalid SIPP code %q: expected 4 letters", code)
+	}
+
+	category, ok := categories[code[0]]
+	if !ok {
+		return models.VehicleAttributes{}, fmt.Errorf("invalid SIPP code %q: unknown category %q", code, code[0])
+	}
+	bodyType, ok := bodyTypes[code[1]]
+	if !ok {
+		return models.VehicleAttributes{}, fmt.Errorf("invalid SIPP code %q: unknown body type %q", code, code[1])
+	}
+	train, ok := driveTrains[code[2]]
+	if !ok {
+		return models.VehicleAttributes{}, fmt.Errorf("invalid SIPP code %q: unknown transmission %q", code, code[2])
+	}
+	fa, ok := fuelAirs[code[3]]
+	if !ok {
+		return models.VehicleAttributes{}, fmt.Errorf("invalid SIPP code %q: unknown fuel/air %q", code, code[3])
+	}
+
+	return models.VehicleAttributes{
+		Code:            code,
+		Category:        category,
+		BodyType:        bodyType,
+		Transmission:    train.transmission,
+		Drive:           train.drive,
+		Fuel:            fa.fuel,
+		AirConditioning: fa.air,
+	}, nil
+}
+
+// CategoryRank returns the position of a decoded category in ACRISS size
+// order. Unknown categories sort last.
+func CategoryRank(category string) int {
+	for i, letter := range categoryOrder {
+		if categories[letter] == category {
+			return i
+		}
+	}
+	return len(categoryOrder)
+}
+
+// DecodeOrEmpty is Decode for transformers: an unknown code only keeps the
+// raw value so the vehicle is still returned.
+func DecodeOrEmpty(code string) models.VehicleAttributes {
+	attributes, err := Decode(code)
+	if err != nil {
+		return models.VehicleAttributes{Code: strings.ToUpper(strings.TrimSpace(code))}
+	}
+	return attributes
+}
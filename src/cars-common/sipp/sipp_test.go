@@ -0,0 +1,73 @@
+package sipp
+
+import (
+	"testing"
+)
+
+func TestDecode_EconomyManual(t *testing.T) {
+	attributes, err := Decode("ECMR")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if attributes.Category != "economy" {
+		t.Errorf("expected category economy, got %s", attributes.Category)
+	}
+	if attributes.BodyType != "2_4_door" {
+		t.Errorf("expected body type 2_4_door, got %s", attributes.BodyType)
+	}
+	if attributes.Transmission != TransmissionManual {
+		t.Errorf("expected manual transmission, got %s", attributes.Transmission)
+	}
+	if attributes.Fuel != "unspecified" || !attributes.AirConditioning {
+		t.Errorf("expected unspecified fuel with air, got %s/%v", attributes.Fuel, attributes.AirConditioning)
+	}
+}
+
+func TestDecode_SUVAutomaticAWD(t *testing.T) {
+	attributes, err := Decode("sfdd")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if attributes.Code != "SFDD" {
+		t.Errorf("expected normalized code SFDD, got %s", attributes.Code)
+	}
+	if attributes.BodyType != "suv" {
+		t.Errorf("expected body type suv, got %s", attributes.BodyType)
+	}
+	if attributes.Transmission != TransmissionAutomatic || attributes.Drive != DriveAllWheel {
+		t.Errorf("expected automatic awd, got %s/%s", attributes.Transmission, attributes.Drive)
+	}
+	if attributes.Fuel != "diesel" {
+		t.Errorf("expected diesel, got %s", attributes.Fuel)
+	}
+}
+
+func TestDecode_PlugInHybridAndLongRangeElectric(t *testing.T) {
+	for code, fuel := range map[string]string{"CDAI": "hybrid", "CDAC": "electric"} {
+		attributes, err := Decode(code)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", code, err)
+		}
+		if attributes.Fuel != fuel || !attributes.AirConditioning {
+			t.Errorf("%s: expected %s with air, got %s/%v", code, fuel, attributes.Fuel, attributes.AirConditioning)
+		}
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	for _, code := range []string{"", "ECM", "ECMRX", "QCMR", "EAMR", "ECKR", "ECMK"} {
+		if _, err := Decode(code); err == nil {
+			t.Errorf("expected error for %q, got nil", code)
+		}
+	}
+}
+
+func TestDecodeOrEmpty_KeepsCode(t *testing.T) {
+	attributes := DecodeOrEmpty("zzzz")
+	if attributes.Code != "ZZZZ" {
+		t.Errorf("expected code ZZZZ, got %s", attributes.Code)
+	}
+	if attributes.Category != "" {
+		t.Errorf("expected empty category, got %s", attributes.Category)
+	}
+}
@@ -0,0 +1,132 @@
+package transformers
+
+import (
+	"strconv"
+
+	"cars-common/models"
+)
+
+type CarTransformer interface {
+	Transform(data map[string]interface{}, config models.TransformationConfig) (models.StandardResponse, error)
+	CanTransform(data map[string]interface{}) bool
+	GetProvider() models.Provider
+}
+
+type BookingTransformer interface {
+	Transform(data map[string]interface{}) models.Booking
+	CanTransform(data map[string]interface{}) bool
+	GetProvider() models.Provider
+}
+
+type TransformerRegistry struct {
+	transformers map[models.Provider]CarTransformer
+}
+
+func NewTransformerRegistry() *TransformerRegistry {
+	return &TransformerRegistry{
+		transformers: make(map[models.Provider]CarTransformer),
+	}
+}
+
+func (tr *TransformerRegistry) Register(transformer CarTransformer) {
+	tr.transformers[transformer.GetProvider()] = transformer
+}
+
+func (tr *TransformerRegistry) Get(provider models.Provider) (CarTransformer, bool) {
+	transformer, exists := tr.transformers[provider]
+	return transformer, exists
+}
+
+func (tr *TransformerRegistry) FindByData(data map[string]interface{}) (CarTransformer, bool) {
+	for _, transformer := range tr.transformers {
+		if transformer.CanTransform(data) {
+			return transformer, true
+		}
+	}
+	return nil, false
+}
+
+func (tr *TransformerRegistry) GetAll() map[models.Provider]CarTransformer {
+	return tr.transformers
+}
+
+func GetString(m map[string]interface{}, keys ...string) string {
+	if value, ok := getPath(m, keys...).(string); ok {
+		return value
+	}
+	return ""
+}
+
+// GetID reads identifiers that providers send either as strings or numbers.
+func GetID(m map[string]interface{}, keys ...string) string {
+	switch value := getPath(m, keys...).(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return ""
+}
+
+func GetFloat(m map[string]interface{}, keys ...string) float64 {
+	switch value := getPath(m, keys...).(type) {
+	case float64:
+		return value
+	case string:
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return 0
+}
+
+func GetInt(m map[string]interface{}, keys ...string) int {
+	return int(GetFloat(m, keys...))
+}
+
+func GetArrayValue(m map[string]interface{}, key string) []interface{} {
+	if value, exists := m[key]; exists {
+		if array, ok := value.([]interface{}); ok {
+			return array
+		}
+	}
+	return []interface{}{}
+}
+
+func GetMapValue(m map[string]interface{}, key string) map[string]interface{} {
+	if value, exists := m[key]; exists {
+		if mapVal, ok := value.(map[string]interface{}); ok {
+			return mapVal
+		}
+	}
+	return nil
+}
+
+// FirstMap returns the first object of an array field. Fox wraps single
+// objects such as vehicleClass or pricing in one-element arrays.
+func FirstMap(m map[string]interface{}, key string) map[string]interface{} {
+	if mapVal := GetMapValue(m, key); mapVal != nil {
+		return mapVal
+	}
+	for _, item := range GetArrayValue(m, key) {
+		if mapVal, ok := item.(map[string]interface{}); ok {
+			return mapVal
+		}
+	}
+	return map[string]interface{}{}
+}
+
+func getPath(m map[string]interface{}, keys ...string) interface{} {
+	current := m
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			return current[key]
+		}
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = next
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package transformers
+
+import (
+	"log"
+
+	"cars-common/models"
+	"cars-common/sipp"
+)
+
+type FoxTransformer struct{}
+
+func NewFoxTransformer() *FoxTransformer {
+	return &FoxTransformer{}
+}
+
+func (t *FoxTransformer) GetProvider() models.Provider {
+	return models.ProviderFox
+}
+
+func (t *FoxTransformer) CanTransform(data map[string]interface{}) bool {
+	_, hasOffers := data["rentalOffers"]
+	return hasOffers
+}
+
+func (t *FoxTransformer) Transform(data map[string]interface{}, config models.TransformationConfig) (models.StandardResponse, error) {
+	response := models.StandardResponse{
+		Cars: []models.Car{},
+	}
+
+	rentalLocation := FirstMap(data, "rentalLocation")
+	returnLocation := FirstMap(data, "returnLocation")
+
+	for _, item := range GetArrayValue(data, "rentalOffers") {
+		if offerMap, ok := item.(map[string]interface{}); ok {
+			car := t.TransformOffer(offerMap)
+			t.applyLocations(&car, rentalLocation, returnLocation)
+			response.Cars = append(response.Cars, car)
+		}
+	}
+
+	log.Printf("[FOX-TRANSFORMER] Successfully transformed %d cars", len(response.Cars))
+	return response, nil
+}
+
+func (t *FoxTransformer) TransformOffer(offerMap map[string]interface{}) models.Car {
+	vehicleClass := FirstMap(offerMap, "vehicleClass")
+	pricing := FirstMap(FirstMap(offerMap, "rateProduct"), "pricing")
+
+	base := GetFloat(pricing, "rateCharge")
+	taxes := GetFloat(pricing, "taxCharges")
+	total := GetFloat(pricing, "totalCharge")
+	if total == 0 {
+		total = base + taxes
+	}
+
+	return models.Car{
+		Provider: models.ProviderFox,
+		Supplier: string(models.ProviderFox),
+		Name:     GetString(vehicleClass, "classDesc"),
+		Category: GetString(vehicleClass, "classDesc"),
+		ImageURL: GetString(vehicleClass, "classImageURL"),
+		RateCode: GetString(pricing, "priceCode"),
+		Days:     GetInt(pricing, "rentalDays"),
+		Vehicle:  sipp.DecodeOrEmpty(GetString(vehicleClass, "classCode")),
+		Rate: models.Rate{
+			BasePrice:  base,
+			Taxes:      taxes,
+			TotalPrice: total,
+			Currency:   GetString(pricing, "currencyCode"),
+		},
+		Fees: t.surcharges(pricing),
+	}
+}
+
+func (t *FoxTransformer) applyLocations(car *models.Car, rentalLocation, returnLocation map[string]interface{}) {
+	rentalCode := GetString(rentalLocation, "locationCode")
+	returnCode := GetString(returnLocation, "locationCode")
+
+	car.OneWay = rentalCode != "" && returnCode != "" && rentalCode != returnCode
+	car.PickupLocationType = LocationType(GetString(rentalLocation, "locationType"), GetString(rentalLocation, "type"))
+	car.ReturnLocationType = LocationType(GetString(returnLocation, "locationType"), GetString(returnLocation, "type"))
+}
+
+func (t *FoxTransformer) surcharges(pricing map[string]interface{}) []models.Fee {
+	fees := []models.Fee{}
+	for _, item := range GetArrayValue(pricing, "fees") {
+		fee, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		description := GetString(fee, "description")
+		if feeType := FeeType(GetString(fee, "feeCode"), description); feeType != "" {
+			fees = append(fees, models.Fee{
+				Type:        feeType,
+				Description: description,
+				Amount:      GetFloat(fee, "amount"),
+			})
+		}
+	}
+	return fees
+}
@@ -0,0 +1,54 @@
+package transformers
+
+import (
+	"strings"
+
+	"cars-common/models"
+)
+
+var airportMarkers = []string{"airport", "aeropuerto", "aeroporto"}
+
+var oneWayMarkers = []string{"one way", "one-way", "oneway", "drop off", "dropoff", "drop-off", "solo ida", "retorno en otra"}
+
+// LocationType normalizes the provider place type (Rently's type or
+// serviceType, Fox's locationType) to airport or downtown. Empty values stay
+// empty so an unknown place is not reported as downtown.
+func LocationType(values ...string) string {
+	known := false
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value == "" {
+			continue
+		}
+		known = true
+		if containsAny(value, airportMarkers) {
+			return models.LocationTypeAirport
+		}
+	}
+	if known {
+		return models.LocationTypeDowntown
+	}
+	return ""
+}
+
+// FeeType classifies a price item as a one-way or airport surcharge from its
+// type or description. Anything else returns an empty string.
+func FeeType(values ...string) string {
+	text := strings.ToLower(strings.Join(values, " "))
+	switch {
+	case containsAny(text, oneWayMarkers):
+		return models.FeeTypeOneWay
+	case containsAny(text, airportMarkers):
+		return models.FeeTypeAirport
+	}
+	return ""
+}
+
+func containsAny(value string, markers []string) bool {
+	for _, marker := range markers {
+		if strings.Contains(value, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package transformers
+
+import (
+	"log"
+	"strings"
+
+	"cars-common/models"
+	"cars-common/sipp"
+)
+
+type RentlyTransformer struct{}
+
+func NewRentlyTransformer() *RentlyTransformer {
+	return &RentlyTransformer{}
+}
+
+func (t *RentlyTransformer) GetProvider() models.Provider {
+	return models.ProviderRently
+}
+
+func (t *RentlyTransformer) CanTransform(data map[string]interface{}) bool {
+	_, hasCollection := data["collection"]
+	return hasCollection
+}
+
+func (t *RentlyTransformer) Transform(data map[string]interface{}, config models.TransformationConfig) (models.StandardResponse, error) {
+	response := models.StandardResponse{
+		Cars: []models.Car{},
+	}
+
+	for _, item := range GetArrayValue(data, "collection") {
+		if carMap, ok := item.(map[string]interface{}); ok {
+			if response.Pickup == nil {
+				response.Pickup = t.location(carMap, "deliveryPlace", "fromDate")
+				response.Dropoff = t.location(carMap, "returnPlace", "toDate")
+			}
+			response.Cars = append(response.Cars, t.TransformCar(carMap))
+		}
+	}
+
+	log.Printf("[RENTLY-TRANSFORMER] Successfully transformed %d cars", len(response.Cars))
+	return response, nil
+}
+
+func (t *RentlyTransformer) TransformCar(carMap map[string]interface{}) models.Car {
+	code := GetString(carMap, "model", "sipp")
+	if code == "" {
+		code = GetString(carMap, "category", "sipp")
+	}
+
+	total := GetFloat(carMap, "customerPrice")
+	base := t.bookingPrice(carMap)
+	deliveryPlace := GetMapValue(carMap, "deliveryPlace")
+	returnPlace := GetMapValue(carMap, "returnPlace")
+
+	return models.Car{
+		Provider:  models.ProviderRently,
+		Supplier:  GetString(carMap, "supplier", "name"),
+		Name:      GetString(carMap, "model", "name"),
+		Brand:     GetString(carMap, "model", "brand"),
+		Category:  GetString(carMap, "category", "name"),
+		ImageURL:  GetString(carMap, "model", "imagePath"),
+		RateCode:  GetString(carMap, "rateCode"),
+		Days:      GetInt(carMap, "totalDays"),
+		Franchise: GetFloat(carMap, "franchise"),
+		Vehicle:   sipp.DecodeOrEmpty(code),
+		Rate: models.Rate{
+			Price:      GetFloat(carMap, "price"),
+			BasePrice:  base,
+			Taxes:      total - base,
+			TotalPrice: total,
+			Currency:   GetString(carMap, "currency"),
+		},
+		OneWay:             t.isOneWay(deliveryPlace, returnPlace),
+		PickupLocationType: LocationType(GetString(deliveryPlace, "type"), GetString(deliveryPlace, "serviceType")),
+		ReturnLocationType: LocationType(GetString(returnPlace, "type"), GetString(returnPlace, "serviceType")),
+		Fees:               t.surcharges(carMap),
+	}
+}
+
+// location reads a Rently place and its date, e.g. "2025-11-29T11:00:00Z"
+// becomes date "2025-11-29" and time "1100".
+func (t *RentlyTransformer) location(carMap map[string]interface{}, placeKey, dateKey string) *models.Location {
+	place := GetMapValue(carMap, placeKey)
+	location := &models.Location{
+		Code:         GetString(place, "iata"),
+		ID:           GetFloat(place, "id"),
+		Email:        GetString(place, "email"),
+		City:         GetString(place, "city"),
+		Country:      GetString(place, "country"),
+		Address2:     GetString(place, "address2"),
+		ZipCode:      GetString(place, "zipCode"),
+		Type:         GetString(place, "type"),
+		ServiceType:  GetString(place, "serviceType"),
+		Instructions: GetString(place, "pickupInstructions"),
+		Latitude:     GetFloat(place, "latitude"),
+		Longitude:    GetFloat(place, "longitude"),
+		HasPlace:     place != nil,
+	}
+
+	dateParts := strings.Split(GetString(carMap, dateKey), "T")
+	location.Date = dateParts[0]
+	if len(dateParts) > 1 {
+		if timeParts := strings.Split(dateParts[1], ":"); len(timeParts) >= 2 {
+			location.Time = timeParts[0] + timeParts[1]
+		}
+	}
+
+	return location
+}
+
+func (t *RentlyTransformer) isOneWay(deliveryPlace, returnPlace map[string]interface{}) bool {
+	if deliveryPlace == nil || returnPlace == nil {
+		return false
+	}
+	if deliveryID, returnID := GetFloat(deliveryPlace, "id"), GetFloat(returnPlace, "id"); deliveryID != 0 && returnID != 0 {
+		return deliveryID != returnID
+	}
+	return GetString(deliveryPlace, "iata") != GetString(returnPlace, "iata")
+}
+
+func (t *RentlyTransformer) surcharges(carMap map[string]interface{}) []models.Fee {
+	fees := []models.Fee{}
+	for _, item := range GetArrayValue(carMap, "priceItems") {
+		priceItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		description := GetString(priceItem, "description")
+		if feeType := FeeType(GetString(priceItem, "type"), description); feeType != "" {
+			fees = append(fees, models.Fee{
+				Type:        feeType,
+				Description: description,
+				Amount:      GetFloat(priceItem, "price"),
+			})
+		}
+	}
+	return fees
+}
+
+func (t *RentlyTransformer) bookingPrice(carMap map[string]interface{}) float64 {
+	var basePrice float64
+	for _, item := range GetArrayValue(carMap, "priceItems") {
+		if priceItem, ok := item.(map[string]interface{}); ok {
+			if GetString(priceItem, "type") == "Booking" {
+				basePrice += GetFloat(priceItem, "price")
+			}
+		}
+	}
+	return basePrice
+}
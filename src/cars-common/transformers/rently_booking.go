@@ -0,0 +1,123 @@
+package transformers
+
+import (
+	"log"
+
+	"cars-common/models"
+)
+
+// RentlyBookingTransformer maps Rently reservation create, get and cancel
+// responses to the canonical Booking.
+type RentlyBookingTransformer struct {
+	cars *RentlyTransformer
+}
+
+func NewRentlyBookingTransformer() *RentlyBookingTransformer {
+	return &RentlyBookingTransformer{
+		cars: NewRentlyTransformer(),
+	}
+}
+
+func (t *RentlyBookingTransformer) GetProvider() models.Provider {
+	return models.ProviderRently
+}
+
+func (t *RentlyBookingTransformer) CanTransform(data map[string]interface{}) bool {
+	_, hasCustomer := data["customer"]
+	_, hasBookingNumber := data["bookingNumber"]
+	return hasCustomer || hasBookingNumber || t.isCancellation(data)
+}
+
+// isCancellation recognises the cancel response, which only carries the
+// reservation id and its new status.
+func (t *RentlyBookingTransformer) isCancellation(data map[string]interface{}) bool {
+	_, hasCustomer := data["customer"]
+	_, hasModel := data["model"]
+	if hasCustomer || hasModel {
+		return false
+	}
+	_, hasID := data["id"]
+	_, hasBookingID := data["bookingId"]
+	hasStatus := GetString(data, "currentStatus") != "" || GetString(data, "status") != ""
+	return (hasID || hasBookingID) && hasStatus
+}
+
+func (t *RentlyBookingTransformer) Transform(data map[string]interface{}) models.Booking {
+	if t.isCancellation(data) {
+		booking := models.Booking{
+			Provider:           models.ProviderRently,
+			ConfirmationNumber: GetID(data, "id"),
+			Status:             GetString(data, "currentStatus"),
+			Extras:             []models.Extra{},
+		}
+		if booking.ConfirmationNumber == "" {
+			booking.ConfirmationNumber = GetID(data, "bookingId")
+		}
+		if booking.Status == "" {
+			booking.Status = GetString(data, "status")
+		}
+		log.Printf("[RENTLY-BOOKING] Reservation %s is now %s", booking.ConfirmationNumber, booking.Status)
+		return booking
+	}
+
+	booking := models.Booking{
+		Provider:           models.ProviderRently,
+		ConfirmationNumber: GetID(data, "bookingNumber"),
+		Status:             GetString(data, "currentStatus"),
+		Pickup:             t.cars.location(data, "deliveryPlace", "fromDate"),
+		Dropoff:            t.cars.location(data, "returnPlace", "toDate"),
+		Car:                t.cars.TransformCar(data),
+		Driver:             t.driver(GetMapValue(data, "customer")),
+		Extras:             t.extras(data),
+	}
+
+	if booking.ConfirmationNumber == "" {
+		booking.ConfirmationNumber = GetID(data, "id")
+	}
+	if booking.Status == "" {
+		booking.Status = GetString(data, "status")
+	}
+
+	log.Printf("[RENTLY-BOOKING] Transformed reservation %s (%s)", booking.ConfirmationNumber, booking.Status)
+	return booking
+}
+
+func (t *RentlyBookingTransformer) driver(customer map[string]interface{}) models.Driver {
+	return models.Driver{
+		FirstName:      GetString(customer, "name"),
+		LastName:       GetString(customer, "lastName"),
+		Email:          GetString(customer, "emailAddress"),
+		Phone:          GetString(customer, "cellPhone"),
+		DocumentType:   GetString(customer, "documentType"),
+		DocumentNumber: GetID(customer, "documentId"),
+		Country:        GetString(customer, "country"),
+		BirthDate:      GetString(customer, "birthDate"),
+	}
+}
+
+// extras accepts both flat additionals and the {"additional": {...}} shape
+// Rently uses on reservations.
+func (t *RentlyBookingTransformer) extras(data map[string]interface{}) []models.Extra {
+	extras := []models.Extra{}
+	for _, item := range GetArrayValue(data, "additionals") {
+		additionalMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		details := additionalMap
+		if nested := GetMapValue(additionalMap, "additional"); nested != nil {
+			details = nested
+		}
+		quantity := GetInt(additionalMap, "quantity")
+		if quantity == 0 {
+			quantity = 1
+		}
+		extras = append(extras, models.Extra{
+			ID:       GetID(details, "id"),
+			Name:     GetString(details, "name"),
+			Quantity: quantity,
+			Price:    GetFloat(additionalMap, "price"),
+		})
+	}
+	return extras
+}
@@ -0,0 +1,233 @@
+package transformers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+
+	"cars-common/models"
+)
+
+type IRequestFactory interface {
+	BuildRequest() func(map[string]interface{}) func(interface{}) (interface{}, error)
+}
+
+func GetBookingRequestFactory(provider models.Provider, operation models.BookingOperation) (IRequestFactory, error) {
+	if provider != models.ProviderRently {
+		return nil, fmt.Errorf("no booking request factory found for provider: %s", provider)
+	}
+
+	switch operation {
+	case models.BookingCreate:
+		return &RentlyBookingRequestFactory{}, nil
+	case models.BookingGet:
+		return &RentlyBookingGetRequestFactory{}, nil
+	case models.BookingCancel:
+		return &RentlyCancelRequestFactory{}, nil
+	default:
+		return nil, fmt.Errorf("no booking request factory found for operation: %s", operation)
+	}
+}
+
+// RentlyBookingRequestFactory turns a canonical BookingRequest into the body
+// of Rently's reservation create call.
+type RentlyBookingRequestFactory struct{}
+
+func (rp *RentlyBookingRequestFactory) BuildRequest() func(map[string]interface{}) func(interface{}) (interface{}, error) {
+	return func(cfg map[string]interface{}) func(interface{}) (interface{}, error) {
+		return func(input interface{}) (interface{}, error) {
+			req, ok := input.(IRequestWrapper)
+			if !ok {
+				return nil, unknownTypeErr
+			}
+
+			var booking models.BookingRequest
+			if err := decodeBody(req, &booking); err != nil {
+				return nil, fmt.Errorf("invalid booking request: %w", err)
+			}
+			if err := validateBooking(booking); err != nil {
+				return nil, err
+			}
+
+			additionals := make([]map[string]interface{}, 0, len(booking.Extras))
+			for _, extra := range booking.Extras {
+				quantity := extra.Quantity
+				if quantity == 0 {
+					quantity = 1
+				}
+				additionals = append(additionals, map[string]interface{}{
+					"id":       extra.ID,
+					"quantity": quantity,
+				})
+			}
+
+			rentlyBooking := map[string]interface{}{
+				"model":         map[string]interface{}{"id": booking.ModelID},
+				"rateCode":      booking.RateCode,
+				"deliveryPlace": map[string]interface{}{"id": booking.PickupPlaceID},
+				"returnPlace":   map[string]interface{}{"id": booking.DropoffPlaceID},
+				"fromDate":      booking.PickupDateTime,
+				"toDate":        booking.DropoffDateTime,
+				"customer": map[string]interface{}{
+					"name":         booking.Driver.FirstName,
+					"lastName":     booking.Driver.LastName,
+					"emailAddress": booking.Driver.Email,
+					"cellPhone":    booking.Driver.Phone,
+					"documentType": booking.Driver.DocumentType,
+					"documentId":   booking.Driver.DocumentNumber,
+					"country":      booking.Driver.Country,
+					"birthDate":    booking.Driver.BirthDate,
+				},
+				"additionals": additionals,
+				"comments":    booking.Comments,
+			}
+
+			log.Printf("[RENTLY-BOOKING] Creating reservation for model %s", booking.ModelID)
+			return withJSONBody(modifier(req), http.MethodPost, rentlyBooking)
+		}
+	}
+}
+
+// RentlyBookingGetRequestFactory drops any client body or query string so the
+// reservation lookup only carries the booking id in the path.
+type RentlyBookingGetRequestFactory struct{}
+
+func (rp *RentlyBookingGetRequestFactory) BuildRequest() func(map[string]interface{}) func(interface{}) (interface{}, error) {
+	return func(cfg map[string]interface{}) func(interface{}) (interface{}, error) {
+		return func(input interface{}) (interface{}, error) {
+			req, ok := input.(IRequestWrapper)
+			if !ok {
+				return nil, unknownTypeErr
+			}
+			r := modifier(req)
+			r.method = http.MethodGet
+			r.body = io.NopCloser(bytes.NewReader(nil))
+			r.query = url.Values{}
+			return r, nil
+		}
+	}
+}
+
+// RentlyCancelRequestFactory builds Rently's cancellation body. The client
+// may call it with DELETE and an optional reason.
+type RentlyCancelRequestFactory struct{}
+
+func (rp *RentlyCancelRequestFactory) BuildRequest() func(map[string]interface{}) func(interface{}) (interface{}, error) {
+	return func(cfg map[string]interface{}) func(interface{}) (interface{}, error) {
+		return func(input interface{}) (interface{}, error) {
+			req, ok := input.(IRequestWrapper)
+			if !ok {
+				return nil, unknownTypeErr
+			}
+
+			var cancellation models.CancellationRequest
+			if err := decodeBody(req, &cancellation); err != nil {
+				return nil, fmt.Errorf("invalid cancellation request: %w", err)
+			}
+
+			return withJSONBody(modifier(req), http.MethodPost, map[string]interface{}{
+				"reason": cancellation.Reason,
+			})
+		}
+	}
+}
+
+func validateBooking(booking models.BookingRequest) error {
+	switch {
+	case booking.ModelID == "":
+		return errors.New("modelId is required")
+	case booking.PickupPlaceID == "" || booking.DropoffPlaceID == "":
+		return errors.New("pickupPlaceId and dropoffPlaceId are required")
+	case booking.PickupDateTime == "" || booking.DropoffDateTime == "":
+		return errors.New("pickupDateTime and dropoffDateTime are required")
+	case booking.Driver.FirstName == "" || booking.Driver.LastName == "" || booking.Driver.Email == "":
+		return errors.New("driver firstName, lastName and email are required")
+	}
+	return nil
+}
+
+// decodeBody reads the client JSON body. An empty body leaves dest untouched.
+func decodeBody(req IRequestWrapper, dest interface{}) error {
+	body := req.Body()
+	if body == nil {
+		return nil
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func withJSONBody(r requestWrapper, method string, body interface{}) (interface{}, error) {
+	newBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	r.body = io.NopCloser(bytes.NewBuffer(newBody))
+	r.method = method
+	r.query = url.Values{}
+	// Keep the auth and trace headers, only the body changes.
+	headers := http.Header(r.headers).Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	headers.Set("Content-Type", "application/json")
+	headers.Del("Content-Length")
+	r.headers = headers
+	return r, nil
+}
+
+func modifier(req IRequestWrapper) requestWrapper {
+	return requestWrapper{
+		params:  req.Params(),
+		headers: req.Headers(),
+		body:    req.Body(),
+		method:  req.Method(),
+		url:     req.URL(),
+		query:   req.Query(),
+		path:    path.Join(req.Path()),
+	}
+}
+
+var unknownTypeErr = errors.New("unknow request type")
+
+type requestWrapper struct {
+	method  string
+	url     *url.URL
+	query   url.Values
+	path    string
+	body    io.ReadCloser
+	params  map[string]string
+	headers map[string][]string
+}
+
+func (r requestWrapper) Method() string               { return r.method }
+func (r requestWrapper) URL() *url.URL                { return r.url }
+func (r requestWrapper) Query() url.Values            { return r.query }
+func (r requestWrapper) Path() string                 { return r.path }
+func (r requestWrapper) Body() io.ReadCloser          { return r.body }
+func (r requestWrapper) Params() map[string]string    { return r.params }
+func (r requestWrapper) Headers() map[string][]string { return r.headers }
+
+type IRequestWrapper interface {
+	Params() map[string]string
+	Headers() map[string][]string
+	Body() io.ReadCloser
+	Method() string
+	URL() *url.URL
+	Query() url.Values
+	Path() string
+}
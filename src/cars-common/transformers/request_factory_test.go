@@ -0,0 +1,166 @@
+package transformers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"cars-common/models"
+)
+
+type mockRequestWrapper struct {
+	params  map[string]string
+	headers map[string][]string
+	body    []byte
+	method  string
+	url     *url.URL
+	query   url.Values
+	path    string
+}
+
+func (m *mockRequestWrapper) Params() map[string]string    { return m.params }
+func (m *mockRequestWrapper) Headers() map[string][]string { return m.headers }
+func (m *mockRequestWrapper) Body() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(m.body))
+}
+func (m *mockRequestWrapper) Method() string    { return m.method }
+func (m *mockRequestWrapper) URL() *url.URL     { return m.url }
+func (m *mockRequestWrapper) Query() url.Values { return m.query }
+func (m *mockRequestWrapper) Path() string      { return m.path }
+
+const bookingRequest = `{
+  "modelId": "42",
+  "rateCode": "RC1",
+  "pickupPlaceId": "10",
+  "dropoffPlaceId": "11",
+  "pickupDateTime": "2025-11-29T11:00:00Z",
+  "dropoffDateTime": "2025-12-02T11:00:00Z",
+  "driver": {"firstName": "Ana", "lastName": "Perez", "email": "ana@example.com"},
+  "extras": [{"id": "7"}]
+}`
+
+func TestGetBookingRequestFactory_Unknown(t *testing.T) {
+	if _, err := GetBookingRequestFactory(models.ProviderFox, models.BookingCreate); err == nil {
+		t.Fatal("expected error for Fox, got nil")
+	}
+	if _, err := GetBookingRequestFactory(models.ProviderRently, "refund"); err == nil {
+		t.Fatal("expected error for unknown operation, got nil")
+	}
+}
+
+func TestRentlyBookingRequestFactory_BuildRequest(t *testing.T) {
+	factory, err := GetBookingRequestFactory(models.ProviderRently, models.BookingCreate)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	handler := factory.BuildRequest()(map[string]interface{}{})
+
+	result, err := handler(&mockRequestWrapper{body: []byte(bookingRequest), method: "POST", path: "/api/Booking"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	rw, ok := result.(requestWrapper)
+	if !ok {
+		t.Fatalf("expected requestWrapper, got %T", result)
+	}
+
+	body, _ := io.ReadAll(rw.body)
+	var rentlyBody map[string]interface{}
+	if err := json.Unmarshal(body, &rentlyBody); err != nil {
+		t.Fatalf("body is not valid JSON: %v", err)
+	}
+	if GetString(rentlyBody, "customer", "emailAddress") != "ana@example.com" {
+		t.Errorf("expected customer email, got %v", rentlyBody["customer"])
+	}
+	if additionals := GetArrayValue(rentlyBody, "additionals"); len(additionals) != 1 {
+		t.Errorf("expected 1 additional, got %v", additionals)
+	}
+}
+
+func TestRentlyBookingRequestFactory_MissingDriver(t *testing.T) {
+	handler := (&RentlyBookingRequestFactory{}).BuildRequest()(map[string]interface{}{})
+	if _, err := handler(&mockRequestWrapper{body: []byte(`{"modelId": "42"}`)}); err == nil {
+		t.Fatal("expected validation error, got nil")
+	}
+	if _, err := handler("not a request wrapper"); !errors.Is(err, unknownTypeErr) {
+		t.Fatalf("expected unknownTypeErr, got %v", err)
+	}
+}
+
+func TestRentlyBookingTransformer_Transform(t *testing.T) {
+	data := decode(t, `{
+  "id": 9981,
+  "currentStatus": "Confirmed",
+  "customer": {"name": "Ana", "lastName": "Perez", "emailAddress": "ana@example.com", "documentId": 30111222},
+  "model": {"name": "Onix", "sipp": "ECAR"},
+  "additionals": [{"additional": {"id": 7, "name": "GPS"}, "quantity": 1, "price": 15}]
+}`)
+
+	transformer := NewRentlyBookingTransformer()
+	if !transformer.CanTransform(data) {
+		t.Fatal("expected CanTransform to return true")
+	}
+
+	booking := transformer.Transform(data)
+	if booking.ConfirmationNumber != "9981" || booking.Status != "Confirmed" {
+		t.Errorf("unexpected confirmation: %s/%s", booking.ConfirmationNumber, booking.Status)
+	}
+	if booking.Driver.DocumentNumber != "30111222" || booking.Driver.LastName != "Perez" {
+		t.Errorf("unexpected driver: %+v", booking.Driver)
+	}
+	if len(booking.Extras) != 1 || booking.Extras[0].Name != "GPS" || booking.Extras[0].ID != "7" {
+		t.Errorf("unexpected extras: %+v", booking.Extras)
+	}
+	if booking.Car.Vehicle.Category != "economy" {
+		t.Errorf("expected decoded economy car, got %+v", booking.Car.Vehicle)
+	}
+}
+
+func TestRentlyCancelRequestFactory_KeepsHeaders(t *testing.T) {
+	handler := (&RentlyCancelRequestFactory{}).BuildRequest()(map[string]interface{}{})
+
+	result, err := handler(&mockRequestWrapper{
+		body:   []byte(`{"reason": "change of plans"}`),
+		method: "DELETE",
+		headers: map[string][]string{
+			"Authorization":  {"Bearer token"},
+			"X-Request-Id":   {"abc"},
+			"Content-Length": {"31"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	headers := http.Header(result.(requestWrapper).headers)
+	if headers.Get("Authorization") != "Bearer token" || headers.Get("X-Request-Id") != "abc" {
+		t.Errorf("expected the client headers to be kept, got %v", headers)
+	}
+	if headers.Get("Content-Type") != "application/json" || headers.Get("Content-Length") != "" {
+		t.Errorf("expected a JSON content type without the old length, got %v", headers)
+	}
+}
+
+func TestRentlyBookingTransformer_Cancellation(t *testing.T) {
+	data := decode(t, `{"id": 9981, "currentStatus": "Cancelled"}`)
+
+	transformer := NewRentlyBookingTransformer()
+	if !transformer.CanTransform(data) {
+		t.Fatal("expected the cancel response to be recognised")
+	}
+
+	booking := transformer.Transform(data)
+	if booking.ConfirmationNumber != "9981" || booking.Status != "Cancelled" {
+		t.Errorf("unexpected cancellation: %s/%s", booking.ConfirmationNumber, booking.Status)
+	}
+	if booking.Pickup != nil || booking.Driver.LastName != "" {
+		t.Errorf("expected no reservation details, got %+v", booking)
+	}
+
+	if transformer.CanTransform(decode(t, `{"id": 9981}`)) {
+		t.Error("expected a body without status not to be recognised")
+	}
+}
@@ -0,0 +1,105 @@
+package transformers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cars-common/models"
+)
+
+const rentlyResponse = `{
+  "collection": [
+    {
+      "model": {"name": "Onix", "brand": "Chevrolet", "sipp": "ECAR"},
+      "category": {"name": "Economico"},
+      "supplier": {"name": "Rently Miami"},
+      "customerPrice": 150.5,
+      "priceItems": [
+        {"type": "Booking", "price": 100},
+        {"type": "Additional", "description": "One Way Fee", "price": 20},
+        {"type": "Tax", "price": 30.5}
+      ],
+      "deliveryPlace": {"id": 10, "iata": "MIA", "type": "Airport"},
+      "returnPlace": {"id": 11, "iata": "MIA", "serviceType": "Office"},
+      "currency": "USD",
+      "totalDays": "3"
+    }
+  ]
+}`
+
+const foxResponse = `{
+  "rentalOffers": [
+    {
+      "vehicleClass": [{"classCode": "SFAR", "classDesc": "Standard SUV", "classImageURL": "http://img/suv.png"}],
+      "rateProduct": [{"pricing": [{"currencyCode": "USD", "rateCharge": 200, "taxCharges": 40, "rentalDays": 3, "priceCode": "PC1"}]}]
+    }
+  ]
+}`
+
+func decode(t *testing.T, body string) map[string]interface{} {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	return data
+}
+
+func TestRentlyTransformer_Transform(t *testing.T) {
+	transformer := NewRentlyTransformer()
+	data := decode(t, rentlyResponse)
+	if !transformer.CanTransform(data) {
+		t.Fatal("expected CanTransform to return true")
+	}
+
+	resp, err := transformer.Transform(data, models.TransformationConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Cars) != 1 {
+		t.Fatalf("expected 1 car, got %d", len(resp.Cars))
+	}
+
+	car := resp.Cars[0]
+	if car.Vehicle.Category != "economy" || car.Vehicle.Transmission != "automatic" {
+		t.Errorf("expected automatic economy, got %+v", car.Vehicle)
+	}
+	if car.Rate.BasePrice != 100 || car.Rate.TotalPrice != 150.5 || car.Days != 3 {
+		t.Errorf("unexpected rate: %+v days=%d", car.Rate, car.Days)
+	}
+	if !car.OneWay {
+		t.Error("expected a one-way rental")
+	}
+	if car.PickupLocationType != models.LocationTypeAirport || car.ReturnLocationType != models.LocationTypeDowntown {
+		t.Errorf("unexpected location types: %s/%s", car.PickupLocationType, car.ReturnLocationType)
+	}
+	if len(car.Fees) != 1 || car.Fees[0].Type != models.FeeTypeOneWay || car.Fees[0].Amount != 20 {
+		t.Errorf("expected a one-way fee of 20, got %+v", car.Fees)
+	}
+}
+
+func TestFoxTransformer_Transform(t *testing.T) {
+	transformer := NewFoxTransformer()
+	data := decode(t, foxResponse)
+	if !transformer.CanTransform(data) {
+		t.Fatal("expected CanTransform to return true")
+	}
+
+	resp, err := transformer.Transform(data, models.TransformationConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Cars) != 1 {
+		t.Fatalf("expected 1 car, got %d", len(resp.Cars))
+	}
+
+	car := resp.Cars[0]
+	if car.Vehicle.BodyType != "suv" || car.Vehicle.Category != "standard" {
+		t.Errorf("expected standard suv, got %+v", car.Vehicle)
+	}
+	if car.Rate.TotalPrice != 240 || car.RateCode != "PC1" {
+		t.Errorf("unexpected rate: %+v code=%s", car.Rate, car.RateCode)
+	}
+	if car.OneWay || car.PickupLocationType != "" {
+		t.Errorf("expected a round trip with unknown location type, got %v/%s", car.OneWay, car.PickupLocationType)
+	}
+}
@@ -1,13 +1,9 @@
 package main
 
 import (
-	"fmt"
 	"hotels-common/models"
 	"hotels-common/transformers"
 	"log"
-	"math/rand"
-	"redis"
-	"strconv"
 )
 
 type ExpediaTransformer struct {
@@ -20,20 +16,20 @@ func ExpediaTransformerImpl() *ExpediaTransformer {
 	}
 }
 func (t *ExpediaTransformer) PriceCheckStrategy(data map[string]interface{}, config models.TransformationConfig) string {
-	redisConfig, err := redis.LoadConfig(config.ExtraConfig)
-	if err != nil {
-		_ = fmt.Errorf("error loading Redis config: %v", err)
-	}
-	redisClient, err := redis.NewRedisClient(redisConfig)
-	if err != nil {
-		_ = fmt.Errorf("error creating Redis client: %v", err)
+	if config.PriceChecks == nil {
+		log.Printf("[EXPEDIA-TRANSFORMER] Price checks are not configured")
+		return ""
 	}
-	key := redis.NewUUIDKey("expedia:pricecheck:")
-	err = redisClient.Set(key, data)
+	key, err := config.PriceChecks.Issue(config.Context(), string(models.ProviderExpedia), data)
 	if err != nil {
+		log.Printf("[EXPEDIA-TRANSFORMER] Error issuing price check: %v", err)
 		return ""
 	}
-	return strconv.Itoa(rand.Intn(10000000))
+	return key
+}
+
+func (t *ExpediaTransformer) UsesStore() bool {
+	return true
 }
 
 func (t *ExpediaTransformer) GetProvider() models.Provider {
@@ -72,6 +68,7 @@ func (t *ExpediaTransformer) Transform(data map[string]interface{}, config model
 
 func (t *ExpediaTransformer) transformHotelArray(hotelArray []interface{}, config models.TransformationConfig) ([]models.BaseHotel, error) {
 	hotels := make([]models.BaseHotel, 0, len(hotelArray))
+	snapshots := make([]interface{}, 0, len(hotelArray))
 	for _, property := range hotelArray {
 		if propertyResult, exists := property.(map[string]interface{})["properties"]; exists {
 			if propertyArray, ok := propertyResult.([]interface{}); ok {
@@ -79,11 +76,13 @@ func (t *ExpediaTransformer) transformHotelArray(hotelArray []interface{}, confi
 					if hotelMap, ok := hotel.(map[string]interface{}); ok {
 						transformedHotel := t.transformSingleHotel(hotelMap, config)
 						hotels = append(hotels, transformedHotel)
+						snapshots = append(snapshots, hotelMap)
 					}
 				}
 			}
 		}
 	}
+	transformers.AssignPriceCheckKeys(config, models.ProviderExpedia, hotels, snapshots)
 	return hotels, nil
 }
 
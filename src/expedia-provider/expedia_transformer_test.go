@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"hotels-common/models"
+	"hotels-common/pricecheck"
 	"os"
+	"redis"
+	"strings"
 	"testing"
 )
 
@@ -48,6 +52,30 @@ func TestTransform_WithResults(t *testing.T) {
 	}
 }
 
+func TestTransform_WithResultsIssuesPriceChecksInBatch(t *testing.T) {
+	transformer := ExpediaTransformerImpl()
+	store := redis.NewMemoryStore(0)
+	issuer, _ := pricecheck.NewIssuer(store, pricecheck.DefaultConfig())
+	config := models.TransformationConfig{Store: store, PriceChecks: issuer}
+
+	resp, err := transformer.Transform(loadExpediaResponse(t), config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, hotel := range resp.Hotels {
+		if hotel.PriceCheckKey == "" || seen[hotel.PriceCheckKey] {
+			t.Fatalf("Expected a unique price check key per hotel, got %q", hotel.PriceCheckKey)
+		}
+		seen[hotel.PriceCheckKey] = true
+	}
+	payload, err := issuer.Resolve(context.Background(), string(models.ProviderExpedia), resp.Hotels[0].PriceCheckKey)
+	if err != nil || !strings.Contains(string(payload), "118938708") {
+		t.Errorf("Expected the first hotel snapshot, got %s (%v)", payload, err)
+	}
+}
+
 func loadExpediaResponse(t *testing.T) map[string]interface{} {
 	file, err := os.Open("expedia_response.json")
 	if err != nil {
@@ -62,3 +90,44 @@ func loadExpediaResponse(t *testing.T) map[string]interface{} {
 	}
 	return data
 }
+
+func TestTransform_SingleHotelWithMemoryStore(t *testing.T) {
+	transformer := ExpediaTransformerImpl()
+	data := map[string]interface{}{"property_id": "12345"}
+	store := redis.NewMemoryStore(0)
+	issuer, err := pricecheck.NewIssuer(store, pricecheck.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	config := models.TransformationConfig{Store: store, PriceChecks: issuer}
+
+	resp, err := transformer.Transform(data, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(resp.Hotels) != 1 || resp.Hotels[0].PriceCheckKey == "" {
+		t.Fatalf("Expected one hotel with a price check key, got %+v", resp.Hotels)
+	}
+
+	payload, err := issuer.Resolve(context.Background(), string(models.ProviderExpedia), resp.Hotels[0].PriceCheckKey)
+	if err != nil || !strings.Contains(string(payload), "12345") {
+		t.Errorf("Expected stored price check to resolve, got %s (%v)", payload, err)
+	}
+}
+
+func TestTransform_SingleHotelWithoutStore(t *testing.T) {
+	transformer := ExpediaTransformerImpl()
+	t.Setenv("EXPEDIA_TEST_SECRET", "secret")
+	issuer, err := pricecheck.NewIssuer(nil, &pricecheck.Config{TTL: 60, SecretEnv: "EXPEDIA_TEST_SECRET"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := transformer.Transform(map[string]interface{}{"property_id": "12345"}, models.TransformationConfig{PriceChecks: issuer})
+	if err != nil || len(resp.Hotels) != 1 {
+		t.Fatalf("Expected one hotel, got %+v (%v)", resp.Hotels, err)
+	}
+	if _, err := issuer.Resolve(context.Background(), string(models.ProviderExpedia), resp.Hotels[0].PriceCheckKey); err != nil {
+		t.Errorf("Expected sealed price check to resolve, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"hotels-common/core"
+)
+
+const PluginName = "hotels-aggregator"
+
+var pluginCore *core.HotelPluginCore
+
+func main() {}
+
+func init() {
+	pluginCore = core.NewHotelPluginCore()
+	fmt.Println(PluginName, "loaded!!!")
+}
+
+type registerer string
+
+var ModifierRegisterer = registerer(PluginName)
+
+func (r registerer) RegisterModifiers(f func(
+	name string,
+	modifierFactory func(map[string]interface{}) func(interface{}) (interface{}, error),
+	appliesToRequest bool,
+	appliesToResponse bool,
+)) {
+	f(string(r), pluginCore.CreateAggregationModifierFactory(), false, true)
+}
@@ -0,0 +1,226 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"redis"
+)
+
+type Operation string
+
+const (
+	OperationSearch     Operation = "search"
+	OperationPriceCheck Operation = "pricecheck"
+	OperationBooking    Operation = "booking"
+
+	// UnknownTenant counts calls made without a tenant header.
+	UnknownTenant = "unknown"
+
+	dayLayout = "2006-01-02"
+)
+
+var operations = []Operation{OperationSearch, OperationPriceCheck, OperationBooking}
+
+// Usage is what a tenant did with a provider on one day.
+type Usage struct {
+	Day         string  `json:"day,omitempty"`
+	Tenant      string  `json:"tenant"`
+	Provider    string  `json:"provider"`
+	Searches    int64   `json:"searches"`
+	PriceChecks int64   `json:"price_checks"`
+	Bookings    int64   `json:"bookings"`
+	LookToBook  float64 `json:"look_to_book"`
+}
+
+func (u *Usage) add(operation Operation, count int64) {
+	switch operation {
+	case OperationSearch:
+		u.Searches += count
+	case OperationPriceCheck:
+		u.PriceChecks += count
+	case OperationBooking:
+		u.Bookings += count
+	}
+	u.LookToBook = Ratio(u.Searches, u.Bookings)
+}
+
+// Ratio is the look-to-book ratio: searches per booking, computed as if one
+// booking had been made when there are none.
+func Ratio(searches, bookings int64) float64 {
+	return float64(searches) / float64(max(bookings, 1))
+}
+
+// Ledger counts provider calls per tenant, provider and UTC day.
+type Ledger struct {
+	config *Config
+	now    func() time.Time
+}
+
+func NewLedger(config *Config) *Ledger {
+	return &Ledger{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+func (l *Ledger) Config() *Config {
+	return l.config
+}
+
+// Record counts one call of operation.
+func (l *Ledger) Record(ctx context.Context, store redis.Store, tenant, provider string, operation Operation) error {
+	key := counterKey(Day(l.now()), tenant, provider, operation)
+	count, err := store.Increment(ctx, key)
+	if err != nil {
+		return fmt.Errorf("error counting %s for %s: %v", operation, tenant, err)
+	}
+	if count == 1 {
+		if err := store.Expire(ctx, key, l.config.RetentionDays*86400); err != nil {
+			return fmt.Errorf("error setting expiry of %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// Today returns the usage of tenant with provider so far today.
+func (l *Ledger) Today(ctx context.Context, store redis.Store, tenant, provider string) (Usage, error) {
+	day := Day(l.now())
+	usage := Usage{Day: day, Tenant: tenantName(tenant), Provider: provider}
+
+	keys := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		keys = append(keys, counterKey(day, tenant, provider, operation))
+	}
+	values, errs := store.MGet(ctx, keys)
+	if err := errs.Err(); err != nil {
+		return usage, err
+	}
+
+	for i, operation := range operations {
+		if value, exists := values[keys[i]]; exists {
+			count, _ := strconv.ParseInt(value, 10, 64)
+			usage.add(operation, count)
+		}
+	}
+	return usage, nil
+}
+
+// Throttled reports whether the searches of tenant with provider must be
+// refused because its look-to-book ratio today is over the threshold. It
+// never throttles when bookings are not counted.
+func (l *Ledger) Throttled(ctx context.Context, store redis.Store, tenant, provider string) (bool, Usage, error) {
+	if l.config.MaxLookToBook <= 0 || !l.config.CountsBookings() {
+		return false, Usage{}, nil
+	}
+	usage, err := l.Today(ctx, store, tenant, provider)
+	if err != nil {
+		return false, usage, err
+	}
+	return usage.Searches >= l.config.MinSearches && usage.LookToBook > l.config.MaxLookToBook, usage, nil
+}
+
+// Report sums the counters of the days from..to, both included, for one
+// tenant or, when tenant is empty, for all of them.
+func Report(ctx context.Context, store redis.Store, from, to time.Time, tenant string) ([]Usage, error) {
+	namespace := redis.GetNamespace(redis.NamespaceLookToBook)
+	usages := make(map[string]*Usage)
+
+	for day := from.UTC(); !day.After(to.UTC()); day = day.AddDate(0, 0, 1) {
+		parts := []string{Day(day)}
+		if tenant != "" {
+			parts = append(parts, url.QueryEscape(tenant))
+		}
+
+		keys := make([]string, 0)
+		it := store.Scan(ctx, namespace.Pattern(parts...), 100)
+		for it.Next(ctx) {
+			keys = append(keys, it.Key())
+		}
+		if err := it.Err(); err != nil {
+			return nil, fmt.Errorf("error listing counters of %s: %v", Day(day), err)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		values, errs := store.MGet(ctx, keys)
+		if err := errs.Err(); err != nil {
+			return nil, fmt.Errorf("error reading counters of %s: %v", Day(day), err)
+		}
+
+		for _, key := range keys {
+			dayName, tenantName, provider, operation, ok := parseKey(namespace, key)
+			if !ok {
+				continue
+			}
+			id := dayName + "|" + tenantName + "|" + provider
+			usage, exists := usages[id]
+			if !exists {
+				usage = &Usage{Day: dayName, Tenant: tenantName, Provider: provider}
+				usages[id] = usage
+			}
+			count, _ := strconv.ParseInt(values[key], 10, 64)
+			usage.add(operation, count)
+		}
+	}
+
+	report := make([]Usage, 0, len(usages))
+	for _, usage := range usages {
+		report = append(report, *usage)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Day != b.Day {
+			return a.Day < b.Day
+		}
+		if a.Tenant != b.Tenant {
+			return a.Tenant < b.Tenant
+		}
+		return a.Provider < b.Provider
+	})
+	return report, nil
+}
+
+func Day(t time.Time) string {
+	return t.UTC().Format(dayLayout)
+}
+
+func ParseDay(day string) (time.Time, error) {
+	return time.Parse(dayLayout, day)
+}
+
+// counterKey escapes tenant and provider so a ":" in them cannot shift the
+// key parts.
+func counterKey(day, tenant, provider string, operation Operation) string {
+	return redis.GetNamespace(redis.NamespaceLookToBook).Key(
+		day, url.QueryEscape(tenantName(tenant)), url.QueryEscape(provider), string(operation))
+}
+
+func parseKey(namespace redis.Namespace, key string) (day, tenant, provider string, operation Operation, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(key, namespace.Key()), ":")
+	if len(parts) != 4 {
+		return "", "", "", "", false
+	}
+	tenant, err := url.QueryUnescape(parts[1])
+	if err != nil {
+		return "", "", "", "", false
+	}
+	provider, err = url.QueryUnescape(parts[2])
+	if err != nil {
+		return "", "", "", "", false
+	}
+	return parts[0], tenant, provider, Operation(parts[3]), true
+}
+
+func tenantName(tenant string) string {
+	if tenant == "" {
+		return UnknownTenant
+	}
+	return tenant
+}
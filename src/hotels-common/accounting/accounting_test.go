@@ -0,0 +1,96 @@
+package accounting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"redis"
+)
+
+func TestLedger_ThrottlesOverRatio(t *testing.T) {
+	ctx := context.Background()
+	store := redis.NewMemoryStore(0)
+	ledger := NewLedger(&Config{BookingPaths: []string{"/book"}, MaxLookToBook: 3, MinSearches: 4, RetentionDays: 1})
+
+	for i := 0; i < 4; i++ {
+		ledger.Record(ctx, store, "acme", "TBO", OperationSearch)
+	}
+	if throttled, usage, _ := ledger.Throttled(ctx, store, "acme", "TBO"); !throttled || usage.LookToBook != 4 {
+		t.Fatalf("expected acme to be throttled at ratio 4, got %v %+v", throttled, usage)
+	}
+	if throttled, _, _ := ledger.Throttled(ctx, store, "acme", "Expedia"); throttled {
+		t.Error("expected other providers to keep their own ratio")
+	}
+
+	ledger.Record(ctx, store, "acme", "TBO", OperationBooking)
+	ledger.Record(ctx, store, "acme", "TBO", OperationBooking)
+	if throttled, usage, _ := ledger.Throttled(ctx, store, "acme", "TBO"); throttled {
+		t.Errorf("expected bookings to bring the ratio down, got %+v", usage)
+	}
+}
+
+func TestLedger_DoesNotThrottleWithoutBookings(t *testing.T) {
+	ctx := context.Background()
+	store := redis.NewMemoryStore(0)
+	ledger := NewLedger(&Config{MaxLookToBook: 3, MinSearches: 4, RetentionDays: 1})
+
+	for i := 0; i < 10; i++ {
+		ledger.Record(ctx, store, "acme", "TBO", OperationSearch)
+	}
+	if throttled, _, _ := ledger.Throttled(ctx, store, "acme", "TBO"); throttled {
+		t.Error("expected no throttling while bookings are not classified")
+	}
+}
+
+func TestReport_GroupsByDayTenantAndProvider(t *testing.T) {
+	ctx := context.Background()
+	store := redis.NewMemoryStore(0)
+	ledger := NewLedger(DefaultConfig())
+	day := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	ledger.now = func() time.Time { return day }
+
+	ledger.Record(ctx, store, "acme:eu", "TBO", OperationSearch)
+	ledger.Record(ctx, store, "acme:eu", "TBO", OperationSearch)
+	ledger.Record(ctx, store, "acme:eu", "TBO", OperationPriceCheck)
+	ledger.Record(ctx, store, "", "Expedia", OperationSearch)
+	day = day.Add(2 * time.Hour)
+	ledger.Record(ctx, store, "acme:eu", "TBO", OperationBooking)
+
+	report, err := Report(ctx, store, day.AddDate(0, 0, -1), day, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(report) != 3 {
+		t.Fatalf("expected 3 rows, got %+v", report)
+	}
+	first := report[0]
+	if first.Day != "2026-10-18" || first.Tenant != "acme:eu" || first.Searches != 2 || first.PriceChecks != 1 {
+		t.Errorf("unexpected first row %+v", first)
+	}
+	if report[1].Tenant != UnknownTenant || report[2].Bookings != 1 {
+		t.Errorf("unexpected rows %+v", report[1:])
+	}
+
+	filtered, _ := Report(ctx, store, day, day, "acme:eu")
+	if len(filtered) != 1 || filtered[0].Day != "2026-10-19" {
+		t.Errorf("expected only the tenant's booking day, got %+v", filtered)
+	}
+}
+
+func TestConfig_Operation(t *testing.T) {
+	config := &Config{
+		PriceCheckPaths: []string{"/hotels/prebook/*"},
+		BookingPaths:    []string{"/hotels/book"},
+	}
+	for path, expected := range map[string]Operation{
+		"/hotels/search":       OperationSearch,
+		"/hotels/prebook/123":  OperationPriceCheck,
+		"/hotels/book":         OperationBooking,
+		"/hotels/bookings/123": OperationSearch,
+	} {
+		if got := config.Operation(path); got != expected {
+			t.Errorf("%s: expected %s, got %s", path, expected, got)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package accounting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"redis"
+)
+
+type Config struct {
+	Enabled      bool   `json:"enabled"`
+	TenantHeader string `json:"tenant_header"`
+	// PriceCheckPaths and BookingPaths classify provider calls by backend
+	// path; every other call is a search. Patterns follow path.Match and a
+	// trailing "/*" matches every path below.
+	PriceCheckPaths []string `json:"price_check_paths"`
+	BookingPaths    []string `json:"booking_paths"`
+	// MaxLookToBook throttles the searches of a tenant once its daily
+	// searches per booking with a provider exceed it, after MinSearches
+	// searches. 0 only counts, and so does a config without BookingPaths,
+	// where the ratio cannot be measured.
+	MaxLookToBook float64 `json:"max_look_to_book"`
+	MinSearches   int64   `json:"min_searches"`
+	// RetentionDays keeps the daily counters this long.
+	RetentionDays int `json:"retention_days"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:       false,
+		TenantHeader:  "X-Tenant-ID",
+		MinSearches:   1000,
+		RetentionDays: redis.GetNamespace(redis.NamespaceLookToBook).TTL / 86400,
+	}
+}
+
+// LoadConfig reads the "accounting" object of the plugin configuration.
+func LoadConfig(extra map[string]interface{}) (*Config, error) {
+	config := DefaultConfig()
+
+	raw, exists := extra["accounting"]
+	if !exists {
+		return config, nil
+	}
+
+	configData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling accounting config: %v", err)
+	}
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling accounting config: %v", err)
+	}
+
+	if config.TenantHeader == "" {
+		config.TenantHeader = "X-Tenant-ID"
+	}
+	config.TenantHeader = http.CanonicalHeaderKey(config.TenantHeader)
+	if config.MaxLookToBook < 0 {
+		return nil, fmt.Errorf("max_look_to_book must not be negative")
+	}
+	if config.RetentionDays <= 0 {
+		config.RetentionDays = redis.GetNamespace(redis.NamespaceLookToBook).TTL / 86400
+	}
+	return config, nil
+}
+
+// CountsBookings reports whether bookings can be told apart from searches,
+// which the look-to-book ratio needs.
+func (c *Config) CountsBookings() bool {
+	return len(c.BookingPaths) > 0
+}
+
+// Operation classifies a provider call by its backend path.
+func (c *Config) Operation(requestPath string) Operation {
+	switch {
+	case MatchAny(c.BookingPaths, requestPath):
+		return OperationBooking
+	case MatchAny(c.PriceCheckPaths, requestPath):
+		return OperationPriceCheck
+	default:
+		return OperationSearch
+	}
+}
+
+// MatchAny reports whether requestPath matches one of patterns, written as
+// the paths of the config.
+func MatchAny(patterns []string, requestPath string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if matched, err := path.Match(pattern, requestPath); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,19 @@
 package adapters
 
 import (
+	"context"
 	"encoding/json"
 	"hotels-common/models"
 	"log"
-	"math/rand"
+	"net/url"
 	"reflect"
-	"strconv"
 )
 
+type RequestWrapper interface {
+	Query() url.Values
+	Headers() map[string][]string
+}
+
 type ResponseExtractor struct{}
 
 func NewResponseExtractor() *ResponseExtractor {
@@ -31,6 +36,38 @@ func (re *ResponseExtractor) ExtractData(input interface{}) (models.ResponseData
 	return nil, false
 }
 
+// ExtractRequest returns the client request attached to a response wrapper,
+// if the gateway exposes it.
+func (re *ResponseExtractor) ExtractRequest(input interface{}) (RequestWrapper, bool) {
+	requestMethod := reflect.ValueOf(input).MethodByName("Request")
+	if !requestMethod.IsValid() || requestMethod.Type().NumIn() != 0 {
+		return nil, false
+	}
+	results := requestMethod.Call(nil)
+	if len(results) == 0 {
+		return nil, false
+	}
+	request, ok := results[0].Interface().(RequestWrapper)
+	return request, ok
+}
+
+// ExtractContext returns the context of the client request, so work done
+// while transforming stops when the client goes away.
+func (re *ResponseExtractor) ExtractContext(input interface{}) context.Context {
+	type contexter interface {
+		Context() context.Context
+	}
+	if c, ok := input.(contexter); ok && c.Context() != nil {
+		return c.Context()
+	}
+	if request, ok := re.ExtractRequest(input); ok {
+		if c, ok := request.(contexter); ok && c.Context() != nil {
+			return c.Context()
+		}
+	}
+	return context.Background()
+}
+
 func (re *ResponseExtractor) extractFromBytes(data []byte) (models.ResponseData, bool) {
 	var result map[string]interface{}
 	if err := json.Unmarshal(data, &result); err != nil {
@@ -94,11 +131,7 @@ func NewOutputAdapter() *OutputAdapter {
 	return &OutputAdapter{}
 }
 
-func (oa *OutputAdapter) AdaptOutput(response models.StandardResponse, originalInput interface{}) interface{} {
-	outputMap := map[string]interface{}{
-		"hotels": response.Hotels,
-	}
-
+func (oa *OutputAdapter) AdaptOutput(outputMap map[string]interface{}, originalInput interface{}) interface{} {
 	val := reflect.ValueOf(originalInput)
 	if val.Kind() == reflect.Ptr {
 		val = val.Elem()
@@ -114,8 +147,8 @@ func (oa *OutputAdapter) AdaptOutput(response models.StandardResponse, originalI
 						for k := range dataMap {
 							delete(dataMap, k)
 						}
-						for _, v := range outputMap {
-							dataMap[strconv.Itoa(rand.Intn(100))] = v
+						for k, v := range outputMap {
+							dataMap[k] = v
 						}
 						return originalInput
 					}
@@ -0,0 +1,134 @@
+package aggregation
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"hotels-common/models"
+)
+
+// FailedError is returned when no provider of an aggregated search
+// answered. StatusCode is used by the gateway as the response status.
+type FailedError struct {
+	Providers []models.ProviderStatus
+}
+
+func (e *FailedError) Error() string {
+	failures := make([]string, 0, len(e.Providers))
+	for _, status := range e.Providers {
+		failures = append(failures, fmt.Sprintf("%s %s", status.Provider, status.Status))
+	}
+	return "no provider answered: " + strings.Join(failures, ", ")
+}
+
+// StatusCode is 504 when every provider ran out of time and 502 otherwise.
+func (e *FailedError) StatusCode() int {
+	for _, status := range e.Providers {
+		if status.Status != models.ProviderStatusTimeout {
+			return http.StatusBadGateway
+		}
+	}
+	return http.StatusGatewayTimeout
+}
+
+// Merge combines the hotels and provider statuses of every backend group of
+// a merged response. groups maps a group name to its provider; a configured
+// group missing from data failed without a status and is reported as an
+// error. When groups is empty every object at the root is a group.
+func Merge(data map[string]interface{}, groups map[string]models.Provider) models.StandardResponse {
+	response := models.StandardResponse{
+		Hotels:    []models.BaseHotel{},
+		Providers: []models.ProviderStatus{},
+	}
+
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	if len(groups) == 0 {
+		for group, value := range data {
+			if _, ok := value.(map[string]interface{}); ok {
+				names = append(names, group)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	for _, group := range names {
+		provider, exists := groups[group]
+		if !exists {
+			provider = models.Provider(group)
+		}
+
+		groupData, ok := data[group].(map[string]interface{})
+		if !ok {
+			log.Printf("[HOTEL-AGGREGATION] No response from group %s", group)
+			response.Providers = append(response.Providers, models.ProviderStatus{
+				Provider: provider,
+				Status:   models.ProviderStatusError,
+				Error:    "no response",
+			})
+			continue
+		}
+
+		var result groupResult
+		if err := decode(groupData, &result); err != nil {
+			log.Printf("[HOTEL-AGGREGATION] Error reading group %s: %v", group, err)
+			response.Providers = append(response.Providers, models.ProviderStatus{
+				Provider: provider,
+				Status:   models.ProviderStatusError,
+				Error:    "invalid response",
+			})
+			continue
+		}
+
+		response.Hotels = append(response.Hotels, result.hotels()...)
+		if len(result.Providers) == 0 {
+			// Partial results are off for this provider: it answered.
+			result.Providers = []models.ProviderStatus{{Provider: provider, Status: models.ProviderStatusOK}}
+		}
+		response.Providers = append(response.Providers, result.Providers...)
+	}
+
+	return response
+}
+
+// Succeeded reports whether at least one provider answered.
+func Succeeded(response models.StandardResponse) bool {
+	if len(response.Providers) == 0 {
+		return true
+	}
+	for _, status := range response.Providers {
+		if status.OK() {
+			return true
+		}
+	}
+	return false
+}
+
+// groupResult reads a group rendered by a provider modifier with any of the
+// output profiles.
+type groupResult struct {
+	Hotels    []models.BaseHotel      `json:"hotels"`
+	Results   []models.BaseHotel      `json:"results"`
+	Providers []models.ProviderStatus `json:"providers"`
+}
+
+func (r groupResult) hotels() []models.BaseHotel {
+	if r.Hotels != nil {
+		return r.Hotels
+	}
+	return r.Results
+}
+
+func decode(data map[string]interface{}, target interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, target)
+}
@@ -0,0 +1,94 @@
+package aggregation
+
+import (
+	"net/http"
+	"testing"
+
+	"hotels-common/models"
+)
+
+var groups = map[string]models.Provider{
+	"tbo_results":     models.ProviderTBO,
+	"expedia_results": models.ProviderExpedia,
+}
+
+func TestMerge_KeepsResultsOfProvidersThatAnswered(t *testing.T) {
+	response := Merge(map[string]interface{}{
+		"tbo_results": map[string]interface{}{
+			"hotels": []interface{}{
+				map[string]interface{}{"provider": "TBO", "productId": "1"},
+				map[string]interface{}{"provider": "TBO", "productId": "2"},
+			},
+			"providers": []interface{}{
+				map[string]interface{}{"provider": "TBO", "status": "ok", "latencyMs": 420},
+			},
+		},
+		"expedia_results": map[string]interface{}{
+			"results": []interface{}{},
+			"providers": []interface{}{
+				map[string]interface{}{"provider": "Expedia", "status": "timeout", "latencyMs": 8000},
+			},
+		},
+	}, groups)
+
+	if len(response.Hotels) != 2 {
+		t.Fatalf("expected the 2 TBO hotels, got %d", len(response.Hotels))
+	}
+	if len(response.Providers) != 2 {
+		t.Fatalf("expected a status per provider, got %+v", response.Providers)
+	}
+	expedia, tbo := response.Providers[0], response.Providers[1]
+	if expedia.Status != models.ProviderStatusTimeout || expedia.LatencyMs != 8000 {
+		t.Errorf("expected the Expedia timeout, got %+v", expedia)
+	}
+	if !tbo.OK() || tbo.LatencyMs != 420 {
+		t.Errorf("expected TBO ok, got %+v", tbo)
+	}
+	if !Succeeded(response) {
+		t.Error("expected the search to succeed with one provider")
+	}
+}
+
+func TestMerge_ReportsMissingGroups(t *testing.T) {
+	response := Merge(map[string]interface{}{
+		"tbo_results": map[string]interface{}{
+			"hotels": []interface{}{map[string]interface{}{"provider": "TBO"}},
+		},
+	}, groups)
+
+	if len(response.Providers) != 2 {
+		t.Fatalf("expected a status per provider, got %+v", response.Providers)
+	}
+	if status := response.Providers[0]; status.Provider != models.ProviderExpedia || status.Status != models.ProviderStatusError {
+		t.Errorf("expected the missing Expedia group to be an error, got %+v", status)
+	}
+	if status := response.Providers[1]; !status.OK() {
+		t.Errorf("expected a group without status to be ok, got %+v", status)
+	}
+}
+
+func TestMerge_FailsWhenNoProviderAnswered(t *testing.T) {
+	response := Merge(map[string]interface{}{
+		"tbo_results": map[string]interface{}{
+			"hotels":    []interface{}{},
+			"providers": []interface{}{map[string]interface{}{"provider": "TBO", "status": "timeout"}},
+		},
+		"expedia_results": map[string]interface{}{
+			"hotels":    []interface{}{},
+			"providers": []interface{}{map[string]interface{}{"provider": "Expedia", "status": "timeout"}},
+		},
+	}, groups)
+
+	if Succeeded(response) {
+		t.Fatal("expected the search to fail")
+	}
+	err := &FailedError{Providers: response.Providers}
+	if err.StatusCode() != http.StatusGatewayTimeout {
+		t.Errorf("expected 504 when every provider timed out, got %d", err.StatusCode())
+	}
+
+	err.Providers[0].Status = models.ProviderStatusError
+	if err.StatusCode() != http.StatusBadGateway {
+		t.Errorf("expected 502 when a provider failed, got %d", err.StatusCode())
+	}
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"hotels-common/models"
+	"redis"
+)
+
+// Entry is the raw provider response. Storing it before the response
+// modifiers run lets transformer changes apply to cached searches too.
+type Entry struct {
+	StatusCode int                 `json:"statusCode"`
+	Headers    map[string][]string `json:"headers"`
+	Body       []byte              `json:"body"`
+	StoredAt   time.Time           `json:"storedAt"`
+	// FreshUntil is the soft TTL. Past it the entry is stale and served
+	// while a refresh runs, until the key itself expires (the hard TTL).
+	FreshUntil time.Time `json:"freshUntil"`
+	// Negative marks a cached provider error or empty result.
+	Negative bool `json:"negative,omitempty"`
+}
+
+// Fresh reports whether the entry is within its soft TTL. Entries stored
+// before soft TTLs existed are always fresh.
+func (e *Entry) Fresh(now time.Time) bool {
+	return e.FreshUntil.IsZero() || now.Before(e.FreshUntil)
+}
+
+type ResponseCache struct {
+	client redis.Store
+	config *Config
+}
+
+func NewResponseCache(client redis.Store, config *Config) *ResponseCache {
+	return &ResponseCache{
+		client: client,
+		config: config,
+	}
+}
+
+func (rc *ResponseCache) Config() *Config {
+	return rc.config
+}
+
+// Healthy reports whether the store passed its last health check.
+func (rc *ResponseCache) Healthy() bool {
+	return redis.IsHealthy(rc.client)
+}
+
+func (rc *ResponseCache) Get(ctx context.Context, search SearchRequest) (*Entry, bool) {
+	var entry Entry
+	if err := rc.client.GetJSON(ctx, search.Key(), &entry); err != nil {
+		if !redis.IsNil(err) {
+			log.Printf("[HOTEL-CACHE] Error reading %s: %v", search.Key(), err)
+		}
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set stores a successful response. It is fresh for the provider TTL and
+// kept StaleTTL seconds longer for stale-while-revalidate.
+func (rc *ResponseCache) Set(ctx context.Context, search SearchRequest, entry Entry) error {
+	ttl := rc.config.TTL(search.Provider)
+	entry.Negative = false
+	return rc.store(ctx, search, entry, ttl, ttl+rc.config.StaleTTL)
+}
+
+// SetNegative stores a provider error or empty result for NegativeTTL
+// seconds, without a stale window. It does nothing when negative caching is
+// disabled.
+func (rc *ResponseCache) SetNegative(ctx context.Context, search SearchRequest, entry Entry) error {
+	if rc.config.NegativeTTL <= 0 {
+		return nil
+	}
+	entry.Negative = true
+	return rc.store(ctx, search, entry, rc.config.NegativeTTL, rc.config.NegativeTTL)
+}
+
+func (rc *ResponseCache) store(ctx context.Context, search SearchRequest, entry Entry, softTTL, hardTTL int) error {
+	if entry.StoredAt.IsZero() {
+		entry.StoredAt = time.Now()
+	}
+	entry.FreshUntil = entry.StoredAt.Add(time.Duration(softTTL) * time.Second)
+	if err := rc.client.SetWithTTL(ctx, search.Key(), entry, hardTTL); err != nil {
+		return fmt.Errorf("error caching %s: %v", search.Key(), err)
+	}
+	return nil
+}
+
+func (rc *ResponseCache) TTL(provider models.Provider) int {
+	return rc.config.TTL(provider)
+}
+
+// Lock elects the replica that calls the provider for a search. The others
+// wait for its cached result.
+func (rc *ResponseCache) Lock(ctx context.Context, search SearchRequest) (*redis.Lock, bool, error) {
+	return redis.AcquireLock(ctx, rc.client, lockKey(search), rc.config.LockTTL)
+}
+
+// Locked reports whether a replica is still fetching the search.
+func (rc *ResponseCache) Locked(ctx context.Context, search SearchRequest) bool {
+	_, err := rc.client.Get(ctx, lockKey(search))
+	return err == nil
+}
+
+func lockKey(search SearchRequest) string {
+	return "lock:" + search.Key()
+}
+
+// InvalidateProvider drops every cached search of a provider.
+func (rc *ResponseCache) InvalidateProvider(ctx context.Context, provider models.Provider) (int64, error) {
+	return rc.invalidate(ctx, ProviderPattern(provider))
+}
+
+// InvalidateDestination drops the cached searches of a provider for one
+// destination.
+func (rc *ResponseCache) InvalidateDestination(ctx context.Context, provider models.Provider, destination interface{}) (int64, error) {
+	return rc.invalidate(ctx, DestinationPattern(provider, destination))
+}
+
+func (rc *ResponseCache) invalidate(ctx context.Context, pattern string) (int64, error) {
+	deleted, err := rc.client.DeleteByPattern(ctx, pattern)
+	if err != nil {
+		return deleted, fmt.Errorf("error invalidating %s: %v", pattern, err)
+	}
+	log.Printf("[HOTEL-CACHE] Invalidated %d entries matching %s", deleted, pattern)
+	return deleted, nil
+}
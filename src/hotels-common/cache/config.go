@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"hotels-common/models"
+	"redis"
+)
+
+type Config struct {
+	Enabled      bool           `json:"enabled"`
+	DefaultTTL   int            `json:"default_ttl"`
+	ProviderTTLs map[string]int `json:"provider_ttls"`
+	BypassHeader string         `json:"bypass_header"`
+	// BypassTenants may skip the cache with BypassHeader; the header of the
+	// others is dropped. TenantHeader carries the tenant set by api-key-auth.
+	BypassTenants []string `json:"bypass_tenants"`
+	TenantHeader  string   `json:"tenant_header"`
+	// Coalesce makes concurrent identical searches share one provider call,
+	// in process and across replicas through a lock of LockTTL seconds.
+	Coalesce bool `json:"coalesce"`
+	LockTTL  int  `json:"lock_ttl"`
+	// StaleTTL keeps entries this many seconds past their TTL, served while
+	// one background refresh runs. NegativeTTL caches provider errors and
+	// empty results. Both are disabled with 0.
+	StaleTTL    int `json:"stale_ttl"`
+	NegativeTTL int `json:"negative_ttl"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:      false,
+		DefaultTTL:   redis.GetNamespace(redis.NamespaceSearch).TTL,
+		ProviderTTLs: map[string]int{},
+		BypassHeader: "X-Cache-Bypass",
+		TenantHeader: "X-Tenant-ID",
+		LockTTL:      10,
+	}
+}
+
+// LoadConfig reads the "cache" object of the plugin configuration.
+func LoadConfig(extra map[string]interface{}) (*Config, error) {
+	config := DefaultConfig()
+
+	raw, exists := extra["cache"]
+	if !exists {
+		return config, nil
+	}
+
+	configData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling cache config: %v", err)
+	}
+
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling cache config: %v", err)
+	}
+
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = redis.GetNamespace(redis.NamespaceSearch).TTL
+	}
+	if config.BypassHeader == "" {
+		config.BypassHeader = "X-Cache-Bypass"
+	}
+	if config.TenantHeader == "" {
+		config.TenantHeader = "X-Tenant-ID"
+	}
+	config.BypassHeader = http.CanonicalHeaderKey(config.BypassHeader)
+	config.TenantHeader = http.CanonicalHeaderKey(config.TenantHeader)
+	if config.LockTTL <= 0 {
+		config.LockTTL = 10
+	}
+	if config.StaleTTL < 0 {
+		config.StaleTTL = 0
+	}
+	if config.NegativeTTL < 0 {
+		config.NegativeTTL = 0
+	}
+
+	return config, nil
+}
+
+// MayBypass reports whether tenant may skip the cache.
+func (c *Config) MayBypass(tenant string) bool {
+	if tenant == "" {
+		return false
+	}
+	for _, allowed := range c.BypassTenants {
+		if allowed == tenant {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) TTL(provider models.Provider) int {
+	if ttl, exists := c.ProviderTTLs[string(provider)]; exists && ttl > 0 {
+		return ttl
+	}
+	return c.DefaultTTL
+}
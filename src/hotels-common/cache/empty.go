@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// resultFields are the keys that hold the hotel list in provider payloads.
+var resultFields = []string{"HotelResult", "results", "hotels", "Hotels"}
+
+// IsEmpty reports whether a provider payload carries no hotels: an empty
+// JSON value or array, or an object whose result list is missing or empty.
+func IsEmpty(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return true
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(trimmed, &payload); err != nil {
+		return false
+	}
+
+	switch v := payload.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return true
+		}
+		for _, field := range resultFields {
+			if results, exists := v[field]; exists {
+				if results == nil {
+					return true
+				}
+				list, ok := results.([]interface{})
+				return ok && len(list) == 0
+			}
+		}
+		// Single-property responses (e.g. Expedia by property id) have no
+		// result list.
+		return false
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"hotels-common/models"
+	"redis"
+)
+
+var (
+	destinationFields = []string{"CityCode", "HotelCodes", "region_id", "property_id", "destination"}
+	checkInFields     = []string{"CheckIn", "checkin"}
+	checkOutFields    = []string{"CheckOut", "checkout"}
+	occupancyFields   = []string{"PaxRooms", "occupancy"}
+)
+
+// SearchRequest is the canonical form of a provider search. Two requests to
+// the same endpoint for the same destination, dates and occupancy produce
+// the same key no matter the field order of the body or the query string.
+type SearchRequest struct {
+	Provider    models.Provider `json:"provider"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Destination string          `json:"destination"`
+	CheckIn     string          `json:"checkIn"`
+	CheckOut    string          `json:"checkOut"`
+	Occupancy   string          `json:"occupancy"`
+	Params      string          `json:"params"`
+}
+
+// NewSearchRequest reads the query string and JSON body of the outgoing
+// provider request. The body is restored so the request can still be sent.
+func NewSearchRequest(provider models.Provider, req *http.Request) (SearchRequest, error) {
+	fields := make(map[string]interface{})
+
+	for key, values := range req.URL.Query() {
+		if len(values) == 1 {
+			fields[key] = values[0]
+		} else {
+			fields[key] = values
+		}
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return SearchRequest{}, fmt.Errorf("error reading request body: %v", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		if len(bytes.TrimSpace(body)) > 0 {
+			var bodyFields map[string]interface{}
+			if err := json.Unmarshal(body, &bodyFields); err != nil {
+				return SearchRequest{}, fmt.Errorf("error parsing request body: %v", err)
+			}
+			for key, value := range bodyFields {
+				fields[key] = value
+			}
+		}
+	}
+
+	search := SearchRequest{
+		Provider:    provider,
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		Destination: normalizeDestination(takeField(fields, destinationFields)),
+		CheckIn:     canonicalString(takeField(fields, checkInFields)),
+		CheckOut:    canonicalString(takeField(fields, checkOutFields)),
+		Occupancy:   canonicalString(takeField(fields, occupancyFields)),
+		Params:      canonicalString(fields),
+	}
+
+	return search, nil
+}
+
+func (sr SearchRequest) Hash() string {
+	return redis.NewHashKey("", sr)
+}
+
+// Key namespaces the hash by provider and destination so a whole provider or
+// destination can be invalidated with a pattern.
+func (sr SearchRequest) Key() string {
+	return redis.GetNamespace(redis.NamespaceSearch).Key(string(sr.Provider), sr.Destination, sr.Hash())
+}
+
+// ProviderPattern matches every cached search of a provider.
+func ProviderPattern(provider models.Provider) string {
+	return redis.GetNamespace(redis.NamespaceSearch).Pattern(string(provider))
+}
+
+// DestinationPattern matches the cached searches of a provider for one
+// destination, using the same normalization as Key.
+func DestinationPattern(provider models.Provider, destination interface{}) string {
+	return redis.GetNamespace(redis.NamespaceSearch).Pattern(string(provider), normalizeDestination(destination))
+}
+
+func takeField(fields map[string]interface{}, names []string) interface{} {
+	for _, name := range names {
+		if value, exists := fields[name]; exists {
+			delete(fields, name)
+			return value
+		}
+	}
+	return nil
+}
+
+// normalizeDestination sorts hotel code lists; long lists are shortened to a
+// digest so they still fit in a key.
+func normalizeDestination(value interface{}) string {
+	destination := canonicalString(value)
+	if !strings.Contains(destination, ",") {
+		return destination
+	}
+
+	codes := strings.Split(destination, ",")
+	for i := range codes {
+		codes[i] = strings.TrimSpace(codes[i])
+	}
+	sort.Strings(codes)
+
+	sum := sha256.Sum256([]byte(strings.Join(codes, ",")))
+	return "hotels-" + hex.EncodeToString(sum[:])[:16]
+}
+
+// canonicalString relies on encoding/json sorting map keys.
+func canonicalString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
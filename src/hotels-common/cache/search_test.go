@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"hotels-common/models"
+)
+
+func newSearch(t *testing.T, provider models.Provider, target, body string) SearchRequest {
+	req, err := http.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("invalid request: %v", err)
+	}
+	search, err := NewSearchRequest(provider, req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	restored, _ := io.ReadAll(req.Body)
+	if string(restored) != body {
+		t.Fatalf("expected body to be restored, got %q", restored)
+	}
+	return search
+}
+
+func TestNewSearchRequest_CanonicalKey(t *testing.T) {
+	a := newSearch(t, models.ProviderTBO, "http://tbo/search",
+		`{"CheckIn":"2025-12-25","CheckOut":"2025-12-27","HotelCodes":"2,1,3","PaxRooms":[{"Adults":1,"Children":0}]}`)
+	b := newSearch(t, models.ProviderTBO, "http://tbo/search",
+		`{"PaxRooms":[{"Children":0,"Adults":1}],"HotelCodes":"1,3,2","CheckOut":"2025-12-27","CheckIn":"2025-12-25"}`)
+
+	if a.Key() != b.Key() {
+		t.Errorf("expected equal keys, got %s and %s", a.Key(), b.Key())
+	}
+	if a.CheckIn != "2025-12-25" || !strings.HasPrefix(a.Destination, "hotels-") {
+		t.Errorf("unexpected canonical search: %+v", a)
+	}
+	if !strings.HasPrefix(a.Key(), "search:v1:TBO:hotels-") {
+		t.Errorf("expected key namespaced by provider and destination, got %s", a.Key())
+	}
+}
+
+func TestNewSearchRequest_DifferentOccupancy(t *testing.T) {
+	a := newSearch(t, models.ProviderExpedia, "http://expedia/availability?region_id=6260342&checkin=2025-12-17&checkout=2025-12-18&occupancy=1", "")
+	b := newSearch(t, models.ProviderExpedia, "http://expedia/availability?region_id=6260342&checkin=2025-12-17&checkout=2025-12-18&occupancy=2", "")
+
+	if a.Key() == b.Key() {
+		t.Error("expected different keys for different occupancy")
+	}
+	if a.Destination != "6260342" {
+		t.Errorf("expected region as destination, got %s", a.Destination)
+	}
+}
+
+func TestNewSearchRequest_DifferentEndpoint(t *testing.T) {
+	body := `{"CheckIn":"2025-12-25","CheckOut":"2025-12-27","CityCode":"150184"}`
+	search := newSearch(t, models.ProviderTBO, "http://tbo/search", body)
+	preBook := newSearch(t, models.ProviderTBO, "http://tbo/prebook", body)
+
+	if search.Key() == preBook.Key() {
+		t.Error("expected different keys for different paths")
+	}
+}
+
+func TestDestinationPattern_MatchesKey(t *testing.T) {
+	search := newSearch(t, models.ProviderTBO, "http://tbo/search", `{"CheckIn":"2025-12-25","HotelCodes":"2,1,3"}`)
+
+	pattern := DestinationPattern(models.ProviderTBO, "3,2,1")
+	if !strings.HasPrefix(search.Key(), strings.TrimSuffix(pattern, "*")) {
+		t.Errorf("expected %s to match %s", pattern, search.Key())
+	}
+	if pattern := DestinationPattern(models.ProviderExpedia, "city*"); pattern != `search:v1:Expedia:city\*:*` {
+		t.Errorf("expected glob characters to be escaped, got %s", pattern)
+	}
+}
+
+func TestConfig_TTL(t *testing.T) {
+	config, err := LoadConfig(map[string]interface{}{
+		"cache": map[string]interface{}{
+			"enabled":       true,
+			"provider_ttls": map[string]interface{}{"TBO": 120},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if config.TTL(models.ProviderTBO) != 120 || config.TTL(models.ProviderExpedia) != 300 {
+		t.Errorf("unexpected ttls: TBO=%d Expedia=%d", config.TTL(models.ProviderTBO), config.TTL(models.ProviderExpedia))
+	}
+}
+
+func TestIsEmpty(t *testing.T) {
+	cases := map[string]bool{
+		``:                                  true,
+		`[]`:                                true,
+		`{"HotelResult":[]}`:                true,
+		`{"results":null}`:                  true,
+		`{"HotelResult":[{"HotelCode":1}]}`: false,
+		`[{"property_id":"1"}]`:             false,
+		`{"property_id":"1"}`:               false,
+	}
+	for body, want := range cases {
+		if got := IsEmpty([]byte(body)); got != want {
+			t.Errorf("IsEmpty(%q) = %v, want %v", body, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"hotels-common/accounting"
+)
+
+// ErrLookToBookExceeded is returned for the searches of a tenant whose
+// look-to-book ratio with the provider is over the configured maximum.
+var ErrLookToBookExceeded = errors.New("look-to-book ratio exceeded")
+
+// checkLookToBook refuses searches of tenants over their ratio. The
+// counters are skipped while Redis is unavailable.
+func (pc *ProviderClient) checkLookToBook(req *http.Request, operation accounting.Operation) error {
+	if pc.ledger == nil || operation != accounting.OperationSearch {
+		return nil
+	}
+	store := pc.connectedStore("accounting")
+	if store == nil {
+		return nil
+	}
+
+	tenant := req.Header.Get(pc.ledger.Config().TenantHeader)
+	throttled, usage, err := pc.ledger.Throttled(req.Context(), store, tenant, string(pc.provider))
+	if err != nil {
+		log.Printf("[HOTEL-CLIENT] Not checking look-to-book of %s: %v", tenant, err)
+		return nil
+	}
+	if throttled {
+		return fmt.Errorf("%w: %s has %.0f searches per booking with %s today", ErrLookToBookExceeded, usage.Tenant, usage.LookToBook, pc.provider)
+	}
+	return nil
+}
+
+// account counts a call that reached the provider. Bookings only count
+// when the provider accepted them.
+func (pc *ProviderClient) account(req *http.Request, operation accounting.Operation, statusCode int) {
+	if pc.ledger == nil {
+		return
+	}
+	if operation == accounting.OperationBooking && (statusCode < 200 || statusCode >= 300) {
+		return
+	}
+	store := pc.connectedStore("accounting")
+	if store == nil {
+		return
+	}
+
+	tenant := req.Header.Get(pc.ledger.Config().TenantHeader)
+	if err := pc.ledger.Record(req.Context(), store, tenant, string(pc.provider), operation); err != nil {
+		log.Printf("[HOTEL-CLIENT] %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hotels-common/accounting"
+	"hotels-common/models"
+)
+
+func TestProviderClient_ThrottlesLookToBook(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer provider.Close()
+
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "looktobook-test:",
+		"accounting": map[string]interface{}{
+			"enabled":          true,
+			"booking_paths":    []string{"/book"},
+			"max_look_to_book": 1,
+			"min_searches":     1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	call := func(path string) int {
+		req := httptest.NewRequest(http.MethodPost, provider.URL+path, nil)
+		req.RequestURI = ""
+		req.Header.Set("X-Tenant-ID", "acme")
+		rec := httptest.NewRecorder()
+		pc.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if status := call("/search"); status != http.StatusOK {
+		t.Fatalf("expected the first search to pass, got %d", status)
+	}
+	call("/search")
+	if status := call("/search"); status != http.StatusTooManyRequests {
+		t.Errorf("expected searches over the ratio to be refused, got %d", status)
+	}
+	if status := call("/book"); status != http.StatusOK {
+		t.Errorf("expected bookings to pass while throttled, got %d", status)
+	}
+	call("/book")
+	if status := call("/search"); status != http.StatusOK {
+		t.Errorf("expected bookings to lift the throttle, got %d", status)
+	}
+}
+
+func TestProviderClient_ThrottlesTenantsBeforeTheCache(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"HotelResult":[]}`))
+	}))
+	defer provider.Close()
+
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "looktobook-cache-test:",
+		"cache":      map[string]interface{}{"enabled": true, "coalesce": true},
+		"accounting": map[string]interface{}{
+			"enabled":          true,
+			"booking_paths":    []string{"/book"},
+			"max_look_to_book": 1,
+			"min_searches":     1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	call := func(tenant string) int {
+		req := httptest.NewRequest(http.MethodPost, provider.URL+"/search", strings.NewReader(`{"CityCode":"150184"}`))
+		req.RequestURI = ""
+		req.Header.Set("X-Tenant-ID", tenant)
+		rec := httptest.NewRecorder()
+		pc.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if status := call("globex"); status != http.StatusOK {
+		t.Fatalf("expected globex to search, got %d", status)
+	}
+	for i := 0; i < 2; i++ {
+		pc.ledger.Record(context.Background(), pc.connectedStore("accounting"), "acme", string(models.ProviderTBO), accounting.OperationSearch)
+	}
+
+	if status := call("acme"); status != http.StatusTooManyRequests {
+		t.Errorf("expected acme refused despite the cached search, got %d", status)
+	}
+	if status := call("globex"); status != http.StatusOK {
+		t.Errorf("expected globex served while acme is throttled, got %d", status)
+	}
+}
@@ -0,0 +1,319 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"hotels-common/models"
+)
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+
+	breakerBuckets = 10
+)
+
+// ErrProviderUnavailable is returned while the circuit of the provider host
+// is open. The error itself is an *UnavailableError.
+var ErrProviderUnavailable = errors.New("provider unavailable")
+
+type UnavailableError struct {
+	Provider   models.Provider
+	Host       string
+	RetryAfter time.Duration
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("%s unavailable: circuit open for %s", e.Provider, e.Host)
+}
+
+func (e *UnavailableError) Is(target error) bool {
+	return target == ErrProviderUnavailable
+}
+
+// MarshalJSON is the body clients get instead of waiting for the timeout.
+func (e *UnavailableError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"error":       "provider_unavailable",
+		"message":     e.Error(),
+		"provider":    e.Provider,
+		"host":        e.Host,
+		"retry_after": int(math.Ceil(e.RetryAfter.Seconds())),
+	})
+}
+
+type BreakerConfig struct {
+	Enabled bool `json:"enabled"`
+	// The error and slow call rates are measured over WindowSeconds once
+	// at least MinRequests calls were made in it.
+	WindowSeconds int     `json:"window_seconds"`
+	MinRequests   int     `json:"min_requests"`
+	FailureRate   float64 `json:"failure_rate"`
+	SlowCallMs    int     `json:"slow_call_ms"`
+	SlowCallRate  float64 `json:"slow_call_rate"`
+	// OpenSeconds is how long calls are refused before HalfOpenRequests
+	// probe calls decide whether the circuit closes again.
+	OpenSeconds      int `json:"open_seconds"`
+	HalfOpenRequests int `json:"half_open_requests"`
+}
+
+// LoadBreakerConfig reads the "circuit_breaker" object of the plugin
+// configuration.
+func LoadBreakerConfig(extra map[string]interface{}) (*BreakerConfig, error) {
+	config := &BreakerConfig{
+		WindowSeconds:    30,
+		MinRequests:      10,
+		FailureRate:      0.5,
+		SlowCallMs:       10000,
+		SlowCallRate:     0.8,
+		OpenSeconds:      30,
+		HalfOpenRequests: 3,
+	}
+
+	raw, exists := extra["circuit_breaker"]
+	if !exists {
+		return config, nil
+	}
+
+	configData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling circuit breaker config: %v", err)
+	}
+
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling circuit breaker config: %v", err)
+	}
+
+	switch {
+	case config.WindowSeconds < breakerBuckets:
+		return nil, fmt.Errorf("window_seconds must be at least %d", breakerBuckets)
+	case config.FailureRate <= 0 || config.FailureRate > 1 || config.SlowCallRate <= 0 || config.SlowCallRate > 1:
+		return nil, fmt.Errorf("failure_rate and slow_call_rate must be between 0 and 1")
+	case config.MinRequests <= 0 || config.OpenSeconds <= 0 || config.HalfOpenRequests <= 0 || config.SlowCallMs <= 0:
+		return nil, fmt.Errorf("min_requests, open_seconds, half_open_requests and slow_call_ms must be positive")
+	}
+
+	return config, nil
+}
+
+type breakerBucket struct {
+	start    time.Time
+	calls    int
+	failures int
+	slow     int
+}
+
+// BreakerState is the snapshot exposed for monitoring.
+type BreakerState struct {
+	Provider    models.Provider `json:"provider"`
+	Host        string          `json:"host"`
+	State       string          `json:"state"`
+	Since       time.Time       `json:"since"`
+	Calls       int             `json:"calls"`
+	FailureRate float64         `json:"failure_rate"`
+	SlowRate    float64         `json:"slow_rate"`
+}
+
+// breaker is the circuit of one provider host, local to the replica.
+type breaker struct {
+	provider models.Provider
+	host     string
+	config   *BreakerConfig
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	since    time.Time
+	buckets  [breakerBuckets]breakerBucket
+	probes   int
+	probeOKs int
+}
+
+func newBreaker(provider models.Provider, host string, config *BreakerConfig) *breaker {
+	return &breaker{
+		provider: provider,
+		host:     host,
+		config:   config,
+		now:      time.Now,
+		state:    StateClosed,
+		since:    time.Now(),
+	}
+}
+
+// allow reports whether a call may go out. Calls let through must report
+// their outcome with done.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case StateOpen:
+		openFor := time.Duration(b.config.OpenSeconds) * time.Second
+		if wait := b.since.Add(openFor).Sub(now); wait > 0 {
+			return &UnavailableError{Provider: b.provider, Host: b.host, RetryAfter: wait}
+		}
+		b.transition(StateHalfOpen, now, "open period over")
+		fallthrough
+	case StateHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			return &UnavailableError{Provider: b.provider, Host: b.host, RetryAfter: time.Second}
+		}
+		b.probes++
+	}
+	return nil
+}
+
+// done records the outcome of a call let through by allow.
+func (b *breaker) done(failed bool, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	slow := latency >= time.Duration(b.config.SlowCallMs)*time.Millisecond
+
+	if b.state == StateHalfOpen {
+		if failed || slow {
+			b.transition(StateOpen, now, fmt.Sprintf("probe failed after %s", latency.Round(time.Millisecond)))
+			return
+		}
+		b.probeOKs++
+		if b.probeOKs >= b.config.HalfOpenRequests {
+			b.transition(StateClosed, now, fmt.Sprintf("%d probes succeeded", b.probeOKs))
+		}
+		return
+	}
+	if b.state != StateClosed {
+		// A call sent before the circuit opened.
+		return
+	}
+
+	bucket := b.bucket(now)
+	bucket.calls++
+	if failed {
+		bucket.failures++
+	}
+	if slow {
+		bucket.slow++
+	}
+
+	calls, failureRate, slowRate := b.rates(now)
+	if calls < b.config.MinRequests {
+		return
+	}
+	if failureRate >= b.config.FailureRate || slowRate >= b.config.SlowCallRate {
+		b.transition(StateOpen, now, fmt.Sprintf("failure_rate=%.2f slow_rate=%.2f calls=%d", failureRate, slowRate, calls))
+	}
+}
+
+// release gives back a call let through by allow whose outcome says nothing
+// about the provider, e.g. because the client went away.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// bucket returns the bucket of now, recycling the one it replaces.
+func (b *breaker) bucket(now time.Time) *breakerBucket {
+	width := time.Duration(b.config.WindowSeconds) * time.Second / breakerBuckets
+	start := now.Truncate(width)
+	bucket := &b.buckets[(start.UnixNano()/int64(width))%breakerBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = breakerBucket{start: start}
+	}
+	return bucket
+}
+
+func (b *breaker) rates(now time.Time) (int, float64, float64) {
+	window := time.Duration(b.config.WindowSeconds) * time.Second
+	var calls, failures, slow int
+	for _, bucket := range b.buckets {
+		if now.Sub(bucket.start) >= window {
+			continue
+		}
+		calls += bucket.calls
+		failures += bucket.failures
+		slow += bucket.slow
+	}
+	if calls == 0 {
+		return 0, 0, 0
+	}
+	return calls, float64(failures) / float64(calls), float64(slow) / float64(calls)
+}
+
+// transition changes the state and logs it. Callers hold mu.
+func (b *breaker) transition(state string, now time.Time, reason string) {
+	log.Printf("[HOTEL-BREAKER] provider=%s host=%s from=%s to=%s reason=%q", b.provider, b.host, b.state, state, reason)
+	b.state = state
+	b.since = now
+	b.probes, b.probeOKs = 0, 0
+	if state == StateClosed {
+		b.buckets = [breakerBuckets]breakerBucket{}
+	}
+}
+
+func (b *breaker) snapshot() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	calls, failureRate, slowRate := b.rates(b.now())
+	return BreakerState{
+		Provider:    b.provider,
+		Host:        b.host,
+		State:       b.state,
+		Since:       b.since,
+		Calls:       calls,
+		FailureRate: failureRate,
+		SlowRate:    slowRate,
+	}
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = make(map[string]*breaker)
+)
+
+// getBreaker returns the breaker of the provider host, shared by every
+// client of the provider in the process.
+func getBreaker(provider models.Provider, host string, config *BreakerConfig) *breaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	key := string(provider) + "|" + host
+	b, exists := breakers[key]
+	if !exists {
+		b = newBreaker(provider, host, config)
+		breakers[key] = b
+	}
+	return b
+}
+
+// BreakerStates returns the circuit of every provider host called so far.
+func BreakerStates() []BreakerState {
+	breakersMu.Lock()
+	states := make([]BreakerState, 0, len(breakers))
+	for _, b := range breakers {
+		states = append(states, b.snapshot())
+	}
+	breakersMu.Unlock()
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Provider != states[j].Provider {
+			return states[i].Provider < states[j].Provider
+		}
+		return states[i].Host < states[j].Host
+	})
+	return states
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hotels-common/models"
+)
+
+func testBreakerConfig() *BreakerConfig {
+	return &BreakerConfig{
+		Enabled:          true,
+		WindowSeconds:    10,
+		MinRequests:      4,
+		FailureRate:      0.5,
+		SlowCallMs:       1000,
+		SlowCallRate:     1,
+		OpenSeconds:      5,
+		HalfOpenRequests: 2,
+	}
+}
+
+func TestBreaker_OpensAndRecovers(t *testing.T) {
+	b := newBreaker(models.ProviderTBO, "api.tbo.test", testBreakerConfig())
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+
+	for _, failed := range []bool{false, true, false, true} {
+		if err := b.allow(); err != nil {
+			t.Fatalf("expected closed circuit, got %v", err)
+		}
+		b.done(failed, 10*time.Millisecond)
+	}
+
+	err := b.allow()
+	var unavailable *UnavailableError
+	if !errors.As(err, &unavailable) || !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("expected the circuit to open at 50%% failures, got %v", err)
+	}
+	if unavailable.RetryAfter != 5*time.Second {
+		t.Errorf("expected retry after the open period, got %s", unavailable.RetryAfter)
+	}
+
+	now = now.Add(5 * time.Second)
+	for i := 0; i < 2; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("probe %d: expected half-open circuit to let it through, got %v", i, err)
+		}
+	}
+	if err := b.allow(); err == nil {
+		t.Fatal("expected probes beyond half_open_requests to be refused")
+	}
+	b.done(false, 10*time.Millisecond)
+	b.done(false, 10*time.Millisecond)
+
+	if state := b.snapshot(); state.State != StateClosed || state.Calls != 0 {
+		t.Errorf("expected a closed circuit with a fresh window, got %+v", state)
+	}
+}
+
+func TestBreaker_SlowCallsAndFailedProbe(t *testing.T) {
+	config := testBreakerConfig()
+	config.SlowCallRate = 0.75
+	b := newBreaker(models.ProviderExpedia, "api.expedia.test", config)
+	now := time.Unix(1_700_000_000, 0)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		b.allow()
+		b.done(false, 2*time.Second)
+	}
+	if b.snapshot().State != StateOpen {
+		t.Fatal("expected slow calls to open the circuit")
+	}
+
+	now = now.Add(5 * time.Second)
+	b.allow()
+	b.done(true, 0)
+	if state := b.snapshot(); state.State != StateOpen || !state.Since.Equal(now) {
+		t.Errorf("expected a failed probe to reopen the circuit, got %+v", state)
+	}
+}
+
+func TestProviderClient_ShortCircuits(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer provider.Close()
+
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"circuit_breaker": map[string]interface{}{
+			"enabled":        true,
+			"window_seconds": 10,
+			"min_requests":   2,
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var rec *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, provider.URL+"/search", nil)
+		req.RequestURI = ""
+		rec = httptest.NewRecorder()
+		pc.ServeHTTP(rec, req)
+	}
+
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected 503 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["error"] != "provider_unavailable" {
+		t.Errorf("expected a structured provider_unavailable error, got %s", rec.Body.String())
+	}
+
+	status := httptest.NewRecorder()
+	StatusHandler("/__status", http.NotFoundHandler()).ServeHTTP(status, httptest.NewRequest(http.MethodGet, "/__status", nil))
+	if !json.Valid(status.Body.Bytes()) || status.Code != http.StatusOK {
+		t.Errorf("expected the breaker states, got %d %s", status.Code, status.Body.String())
+	}
+}
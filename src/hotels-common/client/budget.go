@@ -0,0 +1,187 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"hotels-common/accounting"
+	"hotels-common/models"
+)
+
+const maxStatusErrorLength = 200
+
+// BudgetConfig bounds the time a search may spend with the provider so one
+// slow provider does not hold up an aggregated endpoint.
+type BudgetConfig struct {
+	// TimeoutMs covers the whole search, queueing, retries and hedges
+	// included. 0 leaves it to the endpoint timeout.
+	TimeoutMs int `json:"timeout_ms"`
+	// PartialResults answers failed searches with 200 and a provider status
+	// instead of an error, so the gateway keeps the other providers'
+	// results. Successful searches carry the status too.
+	PartialResults bool `json:"partial_results"`
+}
+
+// LoadBudgetConfig reads the "budget" object of the plugin configuration.
+func LoadBudgetConfig(extra map[string]interface{}) (*BudgetConfig, error) {
+	config := &BudgetConfig{}
+
+	raw, exists := extra["budget"]
+	if !exists {
+		return config, nil
+	}
+
+	configData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling budget config: %v", err)
+	}
+
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling budget config: %v", err)
+	}
+
+	if config.TimeoutMs < 0 {
+		return nil, fmt.Errorf("timeout_ms must not be negative")
+	}
+
+	return config, nil
+}
+
+func (c *BudgetConfig) Enabled() bool {
+	return c.TimeoutMs > 0 || c.PartialResults
+}
+
+func (c *BudgetConfig) Timeout() time.Duration {
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
+
+// serveWithinBudget serves a search under the time budget and reports how
+// the provider answered.
+func (pc *ProviderClient) serveWithinBudget(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	if pc.budget.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, pc.budget.Timeout())
+		defer cancel()
+	}
+
+	start := time.Now()
+	response := newBufferedResponse()
+	pc.serve(response, req.WithContext(ctx))
+
+	status := models.ProviderStatus{
+		Provider:  pc.provider,
+		Status:    models.ProviderStatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) && response.code != http.StatusOK:
+		status.Status = models.ProviderStatusTimeout
+		status.Error = context.DeadlineExceeded.Error()
+	case response.code != http.StatusOK:
+		status.Status = models.ProviderStatusError
+		status.Error = statusError(response)
+	}
+
+	if status.Status != models.ProviderStatusOK {
+		log.Printf("[HOTEL-CLIENT] %s search %s after %dms: %s", pc.provider, status.Status, status.LatencyMs, status.Error)
+	}
+	if !pc.budget.PartialResults {
+		response.writeTo(w)
+		return
+	}
+
+	if status.OK() {
+		if body, ok := withProviderStatus(response.body.Bytes(), status); ok {
+			response.body.Reset()
+			response.body.Write(body)
+			response.header.Del("Content-Length")
+		}
+		response.writeTo(w)
+		return
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{models.ProviderStatusKey: status})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// operation classifies the call by its path.
+func (pc *ProviderClient) operation(req *http.Request) accounting.Operation {
+	if pc.operations == nil {
+		return accounting.OperationSearch
+	}
+	return pc.operations.Operation(req.URL.Path)
+}
+
+// withProviderStatus adds the status to a JSON object body. Other bodies,
+// e.g. compressed ones, are left alone.
+func withProviderStatus(body []byte, status models.ProviderStatus) ([]byte, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, false
+	}
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return nil, false
+	}
+	fields[models.ProviderStatusKey] = raw
+
+	result, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+func statusError(response *bufferedResponse) string {
+	message := strings.TrimSpace(response.body.String())
+	if message == "" || strings.HasPrefix(message, "{") {
+		message = http.StatusText(response.code)
+	}
+	if len(message) > maxStatusErrorLength {
+		message = message[:maxStatusErrorLength]
+	}
+	return fmt.Sprintf("status %d: %s", response.code, message)
+}
+
+// bufferedResponse holds a response until its status is known.
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header), code: http.StatusOK}
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) WriteHeader(code int) {
+	r.code = code
+}
+
+func (r *bufferedResponse) Write(p []byte) (int, error) {
+	return r.body.Write(p)
+}
+
+func (r *bufferedResponse) writeTo(w http.ResponseWriter) {
+	for k, hs := range r.header {
+		for _, h := range hs {
+			w.Header().Add(k, h)
+		}
+	}
+	w.WriteHeader(r.code)
+	w.Write(r.body.Bytes())
+}
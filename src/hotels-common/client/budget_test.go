@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hotels-common/models"
+)
+
+func newBudgetClient(t *testing.T, budget map[string]interface{}) *ProviderClient {
+	t.Helper()
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"budget":     budget,
+		"accounting": map[string]interface{}{"booking_paths": []string{"/book"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return pc
+}
+
+func providerStatus(t *testing.T, rec *httptest.ResponseRecorder) (models.ProviderStatus, map[string]interface{}) {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("expected a JSON body, got %q", rec.Body.String())
+	}
+	var status models.ProviderStatus
+	raw, _ := json.Marshal(body[models.ProviderStatusKey])
+	json.Unmarshal(raw, &status)
+	return status, body
+}
+
+func TestProviderClient_ReportsProviderStatus(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			http.Error(w, "upstream exploded", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"HotelResult":[{"HotelCode":"1"}]}`))
+	}))
+	defer provider.Close()
+
+	pc := newBudgetClient(t, map[string]interface{}{"timeout_ms": 1000, "partial_results": true})
+
+	rec := call(pc, context.Background(), provider.URL+"/search")
+	status, body := providerStatus(t, rec)
+	if rec.Code != http.StatusOK || !status.OK() || status.Provider != models.ProviderTBO {
+		t.Errorf("expected an ok status, got %d %+v", rec.Code, status)
+	}
+	if _, exists := body["HotelResult"]; !exists {
+		t.Errorf("expected the provider body to be kept, got %s", rec.Body.String())
+	}
+
+	rec = call(pc, context.Background(), provider.URL+"/broken")
+	status, _ = providerStatus(t, rec)
+	if rec.Code != http.StatusOK || status.Status != models.ProviderStatusError {
+		t.Errorf("expected the failure reported as an error status, got %d %+v", rec.Code, status)
+	}
+}
+
+func TestProviderClient_EnforcesTimeBudget(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer provider.Close()
+
+	pc := newBudgetClient(t, map[string]interface{}{"timeout_ms": 50, "partial_results": true})
+
+	start := time.Now()
+	rec := call(pc, context.Background(), provider.URL+"/search")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the budget to cut the call short, took %s", elapsed)
+	}
+	status, _ := providerStatus(t, rec)
+	if rec.Code != http.StatusOK || status.Status != models.ProviderStatusTimeout || status.LatencyMs < 50 {
+		t.Errorf("expected a timeout status after the budget, got %d %+v", rec.Code, status)
+	}
+}
+
+func TestProviderClient_BudgetWithoutPartialResults(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer provider.Close()
+
+	pc := newBudgetClient(t, map[string]interface{}{"timeout_ms": 1000})
+
+	if rec := call(pc, context.Background(), provider.URL+"/search"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the provider status to be passed through, got %d", rec.Code)
+	}
+	if rec := call(pc, context.Background(), provider.URL+"/book"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected bookings to be left alone, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,516 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hotels-common/accounting"
+	"hotels-common/cache"
+	"hotels-common/degradation"
+	"hotels-common/models"
+	"redis"
+)
+
+const (
+	CacheHeader = "X-Cache"
+	CacheHit    = "HIT"
+	CacheMiss   = "MISS"
+	CacheBypass = "BYPASS"
+	// CacheStale is served past the soft TTL while a refresh runs.
+	CacheStale = "STALE"
+	// CacheNegative is a cached provider error or empty result.
+	CacheNegative = "NEGATIVE"
+
+	refreshTimeout = 60 * time.Second
+
+	coalescePollInterval = 100 * time.Millisecond
+)
+
+// ProviderClient replaces the default backend HTTP client of a hotel
+// provider. It serves identical searches from the response cache and only
+// calls the provider on a miss.
+type ProviderClient struct {
+	provider   models.Provider
+	cache      *cache.ResponseCache
+	httpClient *http.Client
+	flights    *flightGroup
+	limiter    *providerLimiter
+	ledger     *accounting.Ledger
+	operations *accounting.Config
+	breakers   *BreakerConfig
+	retry      *RetryConfig
+	budget     *BudgetConfig
+	latencies  *latencyTracker
+
+	// The Redis store shared by the cache, the limiter and the ledger,
+	// connected again later when Redis was down at start.
+	cacheConfig *cache.Config
+	redisStore  *redis.LazyStore
+}
+
+// NewProviderClient builds the client from the plugin configuration. Redis
+// settings live at the root, the other features under "cache", "limits",
+// "accounting", "circuit_breaker", "retry" and "budget". Without Redis the
+// client works uncached and reconnects later; connections are closed once
+// ctx is done.
+func NewProviderClient(ctx context.Context, provider models.Provider, cfg map[string]interface{}) (*ProviderClient, error) {
+	pc := &ProviderClient{
+		provider:   provider,
+		httpClient: http.DefaultClient,
+		flights:    newFlightGroup(),
+	}
+
+	cacheConfig, err := cache.LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading cache config: %v", err)
+	}
+
+	limitConfig, err := LoadLimitConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading limits config: %v", err)
+	}
+	if limitConfig.Enabled() {
+		pc.limiter = newProviderLimiter(provider, limitConfig)
+		log.Printf("[HOTEL-CLIENT] %s limited to %g calls/s and %d in flight", provider, limitConfig.Rate, limitConfig.MaxInFlight)
+	}
+
+	breakerConfig, err := LoadBreakerConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading circuit breaker config: %v", err)
+	}
+	if breakerConfig.Enabled {
+		pc.breakers = breakerConfig
+	}
+
+	retryConfig, err := LoadRetryConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading retry config: %v", err)
+	}
+	if retryConfig.Enabled() {
+		pc.retry = retryConfig
+		pc.latencies = &latencyTracker{}
+		if len(retryConfig.IdempotentPaths) == 0 {
+			log.Printf("[HOTEL-CLIENT] %s calls not retried: no idempotent_paths configured", provider)
+		}
+	}
+
+	budgetConfig, err := LoadBudgetConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading budget config: %v", err)
+	}
+	if budgetConfig.Enabled() {
+		pc.budget = budgetConfig
+	}
+
+	accountingConfig, err := accounting.LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading accounting config: %v", err)
+	}
+	pc.operations = accountingConfig
+	if accountingConfig.Enabled {
+		pc.ledger = accounting.NewLedger(accountingConfig)
+		if accountingConfig.MaxLookToBook > 0 && !accountingConfig.CountsBookings() {
+			log.Printf("[HOTEL-CLIENT] %s look-to-book ratio not enforced: no booking_paths configured", provider)
+		}
+	}
+
+	if !cacheConfig.Enabled && pc.limiter == nil && pc.ledger == nil {
+		return pc, nil
+	}
+
+	redisConfig, err := redis.LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading Redis config: %v", err)
+	}
+
+	if cacheConfig.Enabled {
+		pc.cacheConfig = cacheConfig
+	}
+	pc.redisStore = redis.NewLazyStore(string(provider), nil, pc.connect(redis.OpenUntilDone(ctx, redisConfig)))
+	pc.redisStore.Get()
+	return pc, nil
+}
+
+// connect wraps open to report Redis as degraded while it fails and to
+// build the cache once it succeeds. The store retries after
+// redis.ReconnectInterval meanwhile.
+func (pc *ProviderClient) connect(open func() (redis.Store, error)) func() (redis.Store, error) {
+	return func() (redis.Store, error) {
+		store, err := open()
+		if err != nil {
+			degradation.Report("redis", fmt.Sprintf("%s Redis unavailable: %v", pc.provider, err))
+			return nil, err
+		}
+		if pc.cacheConfig != nil {
+			pc.cache = cache.NewResponseCache(store, pc.cacheConfig)
+			log.Printf("[HOTEL-CLIENT] %s cache enabled with ttl=%ds", pc.provider, pc.cacheConfig.TTL(pc.provider))
+		}
+		return store, nil
+	}
+}
+
+// connectedStore returns the Redis store when it can be used, nil while it
+// is unreachable. component names the feature degraded meanwhile.
+func (pc *ProviderClient) connectedStore(component string) redis.Store {
+	if pc.redisStore == nil {
+		return nil
+	}
+	store, err := pc.redisStore.Get()
+	if err != nil {
+		degradation.Report(component, fmt.Sprintf("%s %s degraded, Redis unavailable", pc.provider, component))
+		return nil
+	}
+	if !redis.IsHealthy(store) {
+		degradation.Report(component, fmt.Sprintf("%s %s degraded, Redis unhealthy", pc.provider, component))
+		return nil
+	}
+	return store
+}
+
+// responseCache returns the cache when it can be used, nil to skip caching
+// while Redis is unreachable.
+func (pc *ProviderClient) responseCache() *cache.ResponseCache {
+	if pc.cacheConfig == nil || pc.connectedStore("cache") == nil {
+		return nil
+	}
+	return pc.cache
+}
+
+func (pc *ProviderClient) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if pc.budget != nil && pc.operation(req) == accounting.OperationSearch {
+		pc.serveWithinBudget(w, req)
+		return
+	}
+	pc.serve(w, req)
+}
+
+func (pc *ProviderClient) serve(w http.ResponseWriter, req *http.Request) {
+	// The ratio is the tenant's own: check it before the cache and the
+	// coalesced call, which are shared by every tenant.
+	operation := pc.operation(req)
+	if err := pc.checkLookToBook(req, operation); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Price checks and bookings must reach the provider every time.
+	if operation != accounting.OperationSearch || pc.responseCache() == nil {
+		pc.forward(w, req)
+		return
+	}
+
+	search, err := cache.NewSearchRequest(pc.provider, req)
+	if err != nil {
+		log.Printf("[HOTEL-CLIENT] Not caching %s request: %v", pc.provider, err)
+		pc.forward(w, req)
+		return
+	}
+
+	cacheConfig := pc.cache.Config()
+	bypass := req.Header.Get(cacheConfig.BypassHeader) != "" &&
+		cacheConfig.MayBypass(req.Header.Get(cacheConfig.TenantHeader))
+	req.Header.Del(cacheConfig.BypassHeader)
+
+	if !bypass {
+		if entry, hit := pc.cache.Get(req.Context(), search); hit {
+			if !entry.Fresh(time.Now()) {
+				pc.revalidate(req, search)
+				writeEntry(w, entry, CacheStale)
+				return
+			}
+			writeEntry(w, entry, hitStatus(entry))
+			return
+		}
+	}
+
+	if bypass || !pc.cache.Config().Coalesce {
+		entry, err := pc.fetch(req, search)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		status := CacheMiss
+		if bypass {
+			status = CacheBypass
+		}
+		writeEntry(w, entry, status)
+		return
+	}
+
+	entry, status, err := pc.flights.do(req.Context(), search.Key(), func() (*cache.Entry, string, error) {
+		return pc.lead(req, search)
+	})
+	if errors.Is(err, context.Canceled) && req.Context().Err() == nil {
+		// The shared call was cancelled with the leader's request because
+		// its client went away; retry with this one.
+		entry, err = pc.fetch(req, search)
+		status = CacheMiss
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeEntry(w, entry, status)
+}
+
+// lead runs once per search in this process. Across replicas the Redis lock
+// elects one caller; the others wait for its result to appear in the cache.
+func (pc *ProviderClient) lead(req *http.Request, search cache.SearchRequest) (*cache.Entry, string, error) {
+	ctx := req.Context()
+
+	lock, acquired, err := pc.cache.Lock(ctx, search)
+	if err != nil {
+		log.Printf("[HOTEL-CLIENT] Lock unavailable for %s, calling %s directly: %v", search.Key(), pc.provider, err)
+		entry, err := pc.fetch(req, search)
+		return entry, CacheMiss, err
+	}
+
+	if acquired {
+		defer func() {
+			if err := lock.Release(context.Background()); err != nil {
+				log.Printf("[HOTEL-CLIENT] %v", err)
+			}
+		}()
+		// Another replica may have stored the result between our cache
+		// lookup and taking the lock.
+		if entry, hit := pc.cache.Get(ctx, search); hit && entry.Fresh(time.Now()) {
+			return entry, hitStatus(entry), nil
+		}
+		entry, err := pc.fetch(req, search)
+		return entry, CacheMiss, err
+	}
+
+	if entry, ok := pc.waitForEntry(ctx, search); ok {
+		return entry, hitStatus(entry), nil
+	}
+	entry, err := pc.fetch(req, search)
+	return entry, CacheMiss, err
+}
+
+// waitForEntry polls the cache while another replica holds the lock, which
+// it renews for as long as the provider call runs. It gives up when the
+// lock is released or expires without a cached result, or when ctx is done.
+func (pc *ProviderClient) waitForEntry(ctx context.Context, search cache.SearchRequest) (*cache.Entry, bool) {
+	ticker := time.NewTicker(coalescePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-ticker.C:
+		}
+
+		if entry, hit := pc.cache.Get(ctx, search); hit {
+			return entry, true
+		}
+		if !pc.cache.Locked(ctx, search) {
+			// Check once more, the leader stores before releasing.
+			return pc.cache.Get(ctx, search)
+		}
+	}
+}
+
+// fetch calls the provider and caches the outcome: successful responses
+// with the provider TTL, errors and empty results with the negative TTL.
+func (pc *ProviderClient) fetch(req *http.Request, search cache.SearchRequest) (*cache.Entry, error) {
+	ctx := req.Context()
+
+	entry, err := pc.do(req)
+	if err != nil {
+		// Throttling is ours, not the provider's, so it is not cached.
+		if ctx.Err() == nil && !throttled(err) {
+			pc.store(pc.cache.SetNegative(ctx, search, cache.Entry{
+				StatusCode: http.StatusBadGateway,
+				Body:       []byte(err.Error()),
+			}))
+		}
+		return nil, err
+	}
+
+	switch {
+	case entry.StatusCode == http.StatusOK && !cache.IsEmpty(entry.Body):
+		pc.store(pc.cache.Set(ctx, search, *entry))
+	case entry.StatusCode == http.StatusOK || entry.StatusCode >= http.StatusInternalServerError:
+		pc.store(pc.cache.SetNegative(ctx, search, *entry))
+	}
+	return entry, nil
+}
+
+// revalidate refreshes a stale entry in the background, once per search
+// across replicas. The stale entry stays in place if the refresh fails.
+func (pc *ProviderClient) revalidate(req *http.Request, search cache.SearchRequest) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		log.Printf("[HOTEL-CLIENT] Not refreshing %s: %v", search.Key(), err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	refresh := req.Clone(ctx)
+	refresh.Body = io.NopCloser(bytes.NewReader(body))
+
+	go func() {
+		defer cancel()
+		pc.flights.do(ctx, "refresh:"+search.Key(), func() (*cache.Entry, string, error) {
+			lock, acquired, err := pc.cache.Lock(ctx, search)
+			if err != nil || !acquired {
+				return nil, "", err
+			}
+			defer lock.Release(context.Background())
+
+			entry, err := pc.do(refresh)
+			if err != nil {
+				log.Printf("[HOTEL-CLIENT] Refresh of %s failed: %v", search.Key(), err)
+				return nil, "", err
+			}
+			if entry.StatusCode == http.StatusOK && !cache.IsEmpty(entry.Body) {
+				pc.store(pc.cache.Set(ctx, search, *entry))
+				log.Printf("[HOTEL-CLIENT] Refreshed %s", search.Key())
+			}
+			return entry, CacheMiss, nil
+		})
+	}()
+}
+
+func (pc *ProviderClient) store(err error) {
+	if err != nil {
+		log.Printf("[HOTEL-CLIENT] %v", err)
+	}
+}
+
+func hitStatus(entry *cache.Entry) string {
+	if entry.Negative {
+		return CacheNegative
+	}
+	return CacheHit
+}
+
+func (pc *ProviderClient) forward(w http.ResponseWriter, req *http.Request) {
+	entry, err := pc.do(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeEntry(w, entry, "")
+}
+
+// do calls the provider, retrying the calls to idempotent paths when
+// configured. Bookings are never retried, even when listed.
+func (pc *ProviderClient) do(req *http.Request) (*cache.Entry, error) {
+	operation := pc.operation(req)
+	if pc.retry == nil || operation == accounting.OperationBooking || !pc.retry.Idempotent(req.URL.Path) {
+		return pc.attempt(req, operation)
+	}
+	return pc.doWithRetries(req, operation)
+}
+
+// attempt makes one provider call once the circuit breaker and the limiter
+// let it through.
+func (pc *ProviderClient) attempt(req *http.Request, operation accounting.Operation) (*cache.Entry, error) {
+	var circuit *breaker
+	if pc.breakers != nil {
+		circuit = getBreaker(pc.provider, req.URL.Host, pc.breakers)
+		if err := circuit.allow(); err != nil {
+			return nil, err
+		}
+	}
+
+	if pc.limiter != nil {
+		release, err := pc.limiter.acquire(req.Context(), pc.connectedStore("limiter"))
+		if err != nil {
+			if circuit != nil {
+				circuit.release()
+			}
+			return nil, err
+		}
+		defer release()
+	}
+
+	start := time.Now()
+	resp, err := pc.httpClient.Do(req)
+	if circuit != nil {
+		switch {
+		case errors.Is(req.Context().Err(), context.Canceled):
+			circuit.release()
+		case err != nil:
+			circuit.done(true, time.Since(start))
+		default:
+			circuit.done(resp.StatusCode >= http.StatusInternalServerError, time.Since(start))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s: %w", pc.provider, err)
+	}
+	defer resp.Body.Close()
+	pc.account(req, operation, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s response: %v", pc.provider, err)
+	}
+	if pc.latencies != nil && resp.StatusCode < http.StatusInternalServerError {
+		pc.latencies.add(time.Since(start))
+	}
+
+	return &cache.Entry{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       body,
+		StoredAt:   time.Now(),
+	}, nil
+}
+
+// throttled reports errors raised by the gateway before calling the
+// provider.
+func throttled(err error) bool {
+	return errors.Is(err, ErrProviderThrottled) || errors.Is(err, ErrProviderUnavailable)
+}
+
+// writeError answers 503 when the provider quota is exhausted or its
+// circuit is open, so clients retry later, 429 until the next UTC day when
+// the tenant's look-to-book ratio is too high, and 502 for provider
+// failures.
+func writeError(w http.ResponseWriter, err error) {
+	var unavailable *UnavailableError
+	switch {
+	case errors.As(err, &unavailable):
+		body, _ := json.Marshal(unavailable)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(unavailable.RetryAfter.Seconds()))))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write(body)
+	case errors.Is(err, ErrProviderThrottled):
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	case errors.Is(err, ErrLookToBookExceeded):
+		now := time.Now().UTC()
+		midnight := now.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		w.Header().Set("Retry-After", strconv.Itoa(int(midnight.Sub(now).Seconds())+1))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	default:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	}
+}
+
+func writeEntry(w http.ResponseWriter, entry *cache.Entry, cacheStatus string) {
+	for k, hs := range entry.Headers {
+		for _, h := range hs {
+			w.Header().Add(k, h)
+		}
+	}
+	if cacheStatus != "" {
+		w.Header().Set(CacheHeader, cacheStatus)
+	}
+	w.WriteHeader(entry.StatusCode)
+	io.Copy(w, bytes.NewReader(entry.Body))
+}
@@ -0,0 +1,294 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hotels-common/cache"
+	"hotels-common/degradation"
+	"hotels-common/models"
+)
+
+func TestProviderClient_CoalescesIdenticalSearches(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"HotelResult":[]}`))
+	}))
+	defer provider.Close()
+
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "coalesce-test:",
+		"cache":      map[string]interface{}{"enabled": true, "coalesce": true},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	statuses := make([]int, 5)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, provider.URL+"/search",
+				strings.NewReader(`{"CheckIn":"2025-12-25","CheckOut":"2025-12-27","CityCode":"150184"}`))
+			req.RequestURI = ""
+			rec := httptest.NewRecorder()
+			pc.ServeHTTP(rec, req)
+			statuses[i] = rec.Code
+		}(i)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected 1 provider call, got %d", got)
+	}
+	for i, status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("request %d: expected 200, got %d", i, status)
+		}
+	}
+}
+
+func TestFlightGroup_FollowerStopsOnContext(t *testing.T) {
+	g := newFlightGroup()
+	release := make(chan struct{})
+	go g.do(context.Background(), "key", func() (*cache.Entry, string, error) {
+		<-release
+		return &cache.Entry{}, CacheMiss, nil
+	})
+	defer close(release)
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := g.do(ctx, "key", nil); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestProviderClient_FollowerWaitsWhileTheLockIsRenewed(t *testing.T) {
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "follower-test:",
+		"cache":      map[string]interface{}{"enabled": true, "coalesce": true, "lock_ttl": 1},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "http://tbo/search", strings.NewReader(`{"CityCode":"150184"}`))
+	search, _ := cache.NewSearchRequest(models.ProviderTBO, req)
+	lock, acquired, err := pc.responseCache().Lock(context.Background(), search)
+	if !acquired || err != nil {
+		t.Fatalf("expected the lock, got %v %v", acquired, err)
+	}
+	// The leader outlives one lease, renewing it, before storing the result.
+	go func() {
+		time.Sleep(1500 * time.Millisecond)
+		pc.responseCache().Set(context.Background(), search, cache.Entry{
+			StatusCode: http.StatusOK,
+			Body:       []byte(`{"HotelResult":[{"HotelCode":"leader"}]}`),
+			StoredAt:   time.Now(),
+		})
+		lock.Release(context.Background())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	entry, ok := pc.waitForEntry(ctx, search)
+	if !ok || !strings.Contains(string(entry.Body), "leader") {
+		t.Errorf("expected the leader's result, got %v", entry)
+	}
+}
+
+func TestProviderClient_ServesStaleAndRefreshes(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"HotelResult":[{"HotelCode":"fresh"}]}`))
+	}))
+	defer provider.Close()
+
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "stale-test:",
+		"cache":      map[string]interface{}{"enabled": true, "default_ttl": 60, "stale_ttl": 600},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	body := `{"CheckIn":"2025-12-25","CheckOut":"2025-12-27","CityCode":"150184"}`
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, provider.URL+"/search", strings.NewReader(body))
+		req.RequestURI = ""
+		return req
+	}
+	search, _ := cache.NewSearchRequest(models.ProviderTBO, newRequest())
+	pc.responseCache().Set(context.Background(), search, cache.Entry{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"HotelResult":[{"HotelCode":"stale"}]}`),
+		StoredAt:   time.Now().Add(-2 * time.Minute),
+	})
+
+	rec := httptest.NewRecorder()
+	pc.ServeHTTP(rec, newRequest())
+	if rec.Header().Get(CacheHeader) != CacheStale || !strings.Contains(rec.Body.String(), "stale") {
+		t.Fatalf("expected stale entry to be served, got %s %s", rec.Header().Get(CacheHeader), rec.Body.String())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if entry, hit := pc.responseCache().Get(context.Background(), search); hit && entry.Fresh(time.Now()) {
+			if !strings.Contains(string(entry.Body), "fresh") {
+				t.Errorf("expected refreshed body, got %s", entry.Body)
+			}
+			if got := atomic.LoadInt32(&calls); got != 1 {
+				t.Errorf("expected 1 refresh call, got %d", got)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("expected the entry to be refreshed in the background")
+}
+
+func TestProviderClient_CachesEmptyResultsNegatively(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"HotelResult":[]}`))
+	}))
+	defer provider.Close()
+
+	pc, _ := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "negative-test:",
+		"cache":      map[string]interface{}{"enabled": true, "negative_ttl": 30},
+	})
+
+	var statuses []string
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, provider.URL+"/search", strings.NewReader(`{"CityCode":"1"}`))
+		req.RequestURI = ""
+		rec := httptest.NewRecorder()
+		pc.ServeHTTP(rec, req)
+		statuses = append(statuses, rec.Header().Get(CacheHeader))
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected 1 provider call, got %d", got)
+	}
+	if statuses[0] != CacheMiss || statuses[1] != CacheNegative {
+		t.Errorf("expected MISS then NEGATIVE, got %v", statuses)
+	}
+}
+
+func TestProviderClient_CachesOnlySearches(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"HotelResult":[{"HotelCode":"1"}]}`))
+	}))
+	defer provider.Close()
+
+	pc, _ := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "operations-test:",
+		"cache":      map[string]interface{}{"enabled": true},
+		"accounting": map[string]interface{}{
+			"price_check_paths": []string{"/prebook"},
+			"booking_paths":     []string{"/book"},
+		},
+	})
+
+	for _, path := range []string{"/prebook", "/prebook", "/book", "/book"} {
+		req := httptest.NewRequest(http.MethodPost, provider.URL+path, strings.NewReader(`{"CityCode":"1"}`))
+		req.RequestURI = ""
+		rec := httptest.NewRecorder()
+		pc.ServeHTTP(rec, req)
+		if status := rec.Header().Get(CacheHeader); status != "" {
+			t.Errorf("%s: expected no cache status, got %s", path, status)
+		}
+	}
+
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Errorf("expected every price check and booking to reach the provider, got %d calls", got)
+	}
+}
+
+func TestProviderClient_SkipsCacheWhenRedisIsDown(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"HotelResult":[{"HotelCode":"1"}]}`))
+	}))
+	defer provider.Close()
+
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"redis_addr":      "127.0.0.1:1",
+		"dial_timeout_ms": 100,
+		"cache":           map[string]interface{}{"enabled": true},
+	})
+	if err != nil {
+		t.Fatalf("expected degraded client, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, provider.URL+"/search", strings.NewReader(`{"CityCode":"1"}`))
+	req.RequestURI = ""
+	rec := httptest.NewRecorder()
+	pc.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get(CacheHeader) != "" {
+		t.Errorf("expected uncached 200, got %d %q", rec.Code, rec.Header().Get(CacheHeader))
+	}
+	if degradation.Count("cache") == 0 {
+		t.Error("expected the degradation to be reported")
+	}
+}
+
+func TestProviderClient_BypassIsLimitedToAllowedTenants(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"HotelResult":[{"HotelCode":"1"}]}`))
+	}))
+	defer provider.Close()
+
+	pc, _ := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "bypass-test:",
+		"cache":      map[string]interface{}{"enabled": true, "bypass_tenants": []string{"ops"}},
+	})
+
+	search := func(tenant string) string {
+		req := httptest.NewRequest(http.MethodPost, provider.URL+"/search", strings.NewReader(`{"CityCode":"1"}`))
+		req.RequestURI = ""
+		req.Header.Set("X-Tenant-ID", tenant)
+		req.Header.Set("X-Cache-Bypass", "1")
+		rec := httptest.NewRecorder()
+		pc.ServeHTTP(rec, req)
+		return rec.Header().Get(CacheHeader)
+	}
+
+	if status := search("acme"); status != CacheMiss {
+		t.Errorf("expected the first search to be a miss, got %s", status)
+	}
+	if status := search("acme"); status != CacheHit {
+		t.Errorf("expected the bypass of acme to be ignored, got %s", status)
+	}
+	if status := search("ops"); status != CacheBypass {
+		t.Errorf("expected ops to bypass the cache, got %s", status)
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("expected 2 provider calls, got %d", got)
+	}
+}
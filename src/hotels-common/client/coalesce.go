@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"hotels-common/cache"
+)
+
+// flightGroup runs one call per key at a time; concurrent callers with the
+// same key share its result.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done   chan struct{}
+	entry  *cache.Entry
+	status string
+	err    error
+}
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flight)}
+}
+
+// do runs fn unless a call for key is in flight. Followers stop waiting when
+// their own ctx is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (*cache.Entry, string, error)) (*cache.Entry, string, error) {
+	g.mu.Lock()
+	if f, exists := g.calls[key]; exists {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return f.entry, f.status, f.err
+		case <-ctx.Done():
+			return nil, "", ctx.Err()
+		}
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	f.entry, f.status, f.err = fn()
+	close(f.done)
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return f.entry, f.status, f.err
+}
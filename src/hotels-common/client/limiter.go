@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hotels-common/degradation"
+	"hotels-common/models"
+	"redis"
+)
+
+const (
+	slotPollInterval = 50 * time.Millisecond
+	releaseTimeout   = time.Second
+)
+
+// ErrProviderThrottled is returned when a call would exceed the provider
+// quota within the queue timeout.
+var ErrProviderThrottled = errors.New("provider throttled")
+
+// LimitConfig caps the calls to a provider, summed over every replica and
+// every concurrent_calls copy of a request.
+type LimitConfig struct {
+	// Rate is the sustained calls per second and Burst how many may be
+	// made at once after a quiet period. 0 disables the rate limit.
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+	// MaxInFlight caps the calls waiting for a response. 0 disables it.
+	MaxInFlight int `json:"max_in_flight"`
+	// QueueTimeoutMs is how long a call may wait for its turn. 0 fails fast.
+	QueueTimeoutMs int `json:"queue_timeout_ms"`
+	// LeaseTTL frees the in-flight slot of a call whose replica died.
+	LeaseTTL int `json:"lease_ttl"`
+}
+
+// LoadLimitConfig reads the "limits" object of the plugin configuration.
+func LoadLimitConfig(extra map[string]interface{}) (*LimitConfig, error) {
+	config := &LimitConfig{LeaseTTL: 90}
+
+	raw, exists := extra["limits"]
+	if !exists {
+		return config, nil
+	}
+
+	configData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling limits config: %v", err)
+	}
+
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling limits config: %v", err)
+	}
+
+	if config.Rate < 0 || config.MaxInFlight < 0 || config.QueueTimeoutMs < 0 {
+		return nil, fmt.Errorf("limits must not be negative")
+	}
+	if config.Rate > 0 && config.Burst <= 0 {
+		config.Burst = max(int(config.Rate), 1)
+	}
+	if config.LeaseTTL <= 0 {
+		config.LeaseTTL = 90
+	}
+
+	return config, nil
+}
+
+func (c *LimitConfig) Enabled() bool {
+	return c.Rate > 0 || c.MaxInFlight > 0
+}
+
+func (c *LimitConfig) QueueTimeout() time.Duration {
+	return time.Duration(c.QueueTimeoutMs) * time.Millisecond
+}
+
+// providerLimiter applies LimitConfig to the calls of one provider. The
+// quota lives in Redis; while it is unreachable each replica enforces the
+// whole quota locally, so the provider may briefly see more calls.
+type providerLimiter struct {
+	provider models.Provider
+	config   *LimitConfig
+	local    *redis.MemoryStore
+
+	bucketKey string
+	slotsKey  string
+}
+
+func newProviderLimiter(provider models.Provider, config *LimitConfig) *providerLimiter {
+	namespace := redis.GetNamespace(redis.NamespaceQuota)
+	return &providerLimiter{
+		provider:  provider,
+		config:    config,
+		local:     redis.NewMemoryStore(0),
+		bucketKey: namespace.Key(string(provider), "bucket"),
+		slotsKey:  namespace.Key(string(provider), "inflight"),
+	}
+}
+
+// acquire waits for a token and an in-flight slot, up to the queue
+// timeout. The returned release must be called once the call is done.
+func (l *providerLimiter) acquire(ctx context.Context, store redis.Store) (func(), error) {
+	if store == nil {
+		degradation.Report("limiter", fmt.Sprintf("%s quota enforced per replica, Redis unavailable", l.provider))
+		store = l.local
+	}
+
+	deadline := time.Now().Add(l.config.QueueTimeout())
+	if err := l.takeToken(ctx, store, deadline); err != nil {
+		return nil, err
+	}
+	return l.acquireSlot(ctx, store, deadline)
+}
+
+func (l *providerLimiter) takeToken(ctx context.Context, store redis.Store, deadline time.Time) error {
+	if l.config.Rate <= 0 {
+		return nil
+	}
+
+	for {
+		wait, err := store.TakeToken(ctx, l.bucketKey, l.config.Rate, l.config.Burst)
+		if err != nil {
+			if store == l.local {
+				return err
+			}
+			log.Printf("[HOTEL-CLIENT] %s quota unavailable, limiting locally: %v", l.provider, err)
+			store = l.local
+			continue
+		}
+		if wait == 0 {
+			return nil
+		}
+		if !sleepUntil(ctx, deadline, wait) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s rate of %g calls per second", ErrProviderThrottled, l.provider, l.config.Rate)
+		}
+	}
+}
+
+func (l *providerLimiter) acquireSlot(ctx context.Context, store redis.Store, deadline time.Time) (func(), error) {
+	if l.config.MaxInFlight <= 0 {
+		return func() {}, nil
+	}
+
+	holder := uuid.NewString()
+	ttl := time.Duration(l.config.LeaseTTL) * time.Second
+	for {
+		acquired, err := store.AcquireSlot(ctx, l.slotsKey, holder, l.config.MaxInFlight, ttl)
+		if err != nil {
+			if store == l.local {
+				return nil, err
+			}
+			log.Printf("[HOTEL-CLIENT] %s quota unavailable, limiting locally: %v", l.provider, err)
+			store = l.local
+			continue
+		}
+		if acquired {
+			return func() {
+				ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+				defer cancel()
+				if err := store.ReleaseSlot(ctx, l.slotsKey, holder); err != nil {
+					log.Printf("[HOTEL-CLIENT] Error releasing %s call slot: %v", l.provider, err)
+				}
+			}, nil
+		}
+		if !sleepUntil(ctx, deadline, slotPollInterval) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %s has %d calls in flight", ErrProviderThrottled, l.provider, l.config.MaxInFlight)
+		}
+	}
+}
+
+// sleepUntil waits for d unless that would pass the queue deadline.
+func sleepUntil(ctx context.Context, deadline time.Time, d time.Duration) bool {
+	if time.Now().Add(d).After(deadline) {
+		return false
+	}
+	return sleep(ctx, d)
+}
+
+// sleep waits for d and reports false when ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
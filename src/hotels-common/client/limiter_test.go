@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hotels-common/models"
+	"redis"
+)
+
+func TestProviderClient_FailsFastWhenThrottled(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{}`))
+	}))
+	defer provider.Close()
+
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"store":      "memory",
+		"key_prefix": "throttle-test:",
+		"limits":     map[string]interface{}{"rate": 1, "burst": 1},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	statuses := make([]int, 2)
+	for i := range statuses {
+		req := httptest.NewRequest(http.MethodGet, provider.URL+"/search", nil)
+		req.RequestURI = ""
+		rec := httptest.NewRecorder()
+		pc.ServeHTTP(rec, req)
+		statuses[i] = rec.Code
+	}
+
+	if statuses[0] != http.StatusOK || statuses[1] != http.StatusServiceUnavailable {
+		t.Errorf("expected 200 then 503, got %v", statuses)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected 1 provider call, got %d", got)
+	}
+}
+
+func TestProviderLimiter_QueuesForToken(t *testing.T) {
+	limiter := newProviderLimiter(models.ProviderTBO, &LimitConfig{Rate: 20, Burst: 1, QueueTimeoutMs: 500, LeaseTTL: 90})
+	store := redis.NewMemoryStore(0)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := limiter.acquire(context.Background(), store)
+		if err != nil {
+			t.Fatalf("call %d: expected to wait for a token, got %v", i, err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected calls to be spaced by the rate, took %s", elapsed)
+	}
+}
+
+func TestProviderLimiter_CapsInFlight(t *testing.T) {
+	limiter := newProviderLimiter(models.ProviderExpedia, &LimitConfig{MaxInFlight: 2, QueueTimeoutMs: 100, LeaseTTL: 90})
+	store := redis.NewMemoryStore(0)
+
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, err := limiter.acquire(context.Background(), store)
+		if err != nil {
+			t.Fatalf("call %d: expected a slot, got %v", i, err)
+		}
+		releases = append(releases, release)
+	}
+
+	if _, err := limiter.acquire(context.Background(), store); !errors.Is(err, ErrProviderThrottled) {
+		t.Fatalf("expected throttled error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(20 * time.Millisecond)
+		releases[0]()
+	}()
+	release, err := limiter.acquire(context.Background(), store)
+	if err != nil {
+		t.Errorf("expected the queued call to get the released slot, got %v", err)
+	} else {
+		release()
+	}
+	wg.Wait()
+}
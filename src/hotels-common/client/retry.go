@@ -0,0 +1,263 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"hotels-common/accounting"
+	"hotels-common/cache"
+)
+
+const (
+	// minAttemptBudget is the least time left before the deadline worth
+	// starting another attempt with.
+	minAttemptBudget = 250 * time.Millisecond
+
+	latencySamples    = 128
+	minLatencySamples = 20
+)
+
+type RetryConfig struct {
+	// MaxAttempts counts the first call; 1 disables retries.
+	MaxAttempts      int         `json:"max_attempts"`
+	InitialBackoffMs int         `json:"initial_backoff_ms"`
+	MaxBackoffMs     int         `json:"max_backoff_ms"`
+	Hedge            HedgeConfig `json:"hedge"`
+	// IdempotentPaths are the backend paths safe to call more than once,
+	// e.g. searches and price checks, written as the accounting paths.
+	// Calls to any other path are made once and never hedged.
+	IdempotentPaths []string `json:"idempotent_paths"`
+}
+
+// HedgeConfig sends up to MaxHedges duplicates of a call still waiting
+// after the Percentile latency of recent calls, DelayMs until enough calls
+// were seen, and keeps the first answer.
+type HedgeConfig struct {
+	Enabled    bool    `json:"enabled"`
+	Percentile float64 `json:"percentile"`
+	DelayMs    int     `json:"delay_ms"`
+	MinDelayMs int     `json:"min_delay_ms"`
+	MaxHedges  int     `json:"max_hedges"`
+}
+
+// LoadRetryConfig reads the "retry" object of the plugin configuration.
+func LoadRetryConfig(extra map[string]interface{}) (*RetryConfig, error) {
+	config := &RetryConfig{
+		MaxAttempts:      1,
+		InitialBackoffMs: 100,
+		MaxBackoffMs:     2000,
+		Hedge: HedgeConfig{
+			Percentile: 0.95,
+			DelayMs:    3000,
+			MinDelayMs: 100,
+			MaxHedges:  1,
+		},
+	}
+
+	raw, exists := extra["retry"]
+	if !exists {
+		return config, nil
+	}
+
+	configData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling retry config: %v", err)
+	}
+
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling retry config: %v", err)
+	}
+
+	switch {
+	case config.MaxAttempts < 1:
+		return nil, fmt.Errorf("max_attempts must be at least 1")
+	case config.InitialBackoffMs <= 0 || config.MaxBackoffMs < config.InitialBackoffMs:
+		return nil, fmt.Errorf("initial_backoff_ms must be positive and not over max_backoff_ms")
+	case config.Hedge.Enabled && (config.Hedge.Percentile <= 0 || config.Hedge.Percentile >= 1):
+		return nil, fmt.Errorf("hedge percentile must be between 0 and 1")
+	case config.Hedge.Enabled && (config.Hedge.MaxHedges < 1 || config.Hedge.DelayMs <= 0):
+		return nil, fmt.Errorf("hedge max_hedges and delay_ms must be positive")
+	}
+
+	return config, nil
+}
+
+func (c *RetryConfig) Enabled() bool {
+	return c.MaxAttempts > 1 || c.Hedge.Enabled
+}
+
+// Idempotent reports whether calls to the backend path may be retried.
+func (c *RetryConfig) Idempotent(requestPath string) bool {
+	return accounting.MatchAny(c.IdempotentPaths, requestPath)
+}
+
+// backoff is the full-jitter delay before retry number n, starting at 1.
+func (c *RetryConfig) backoff(n int) time.Duration {
+	ceiling := time.Duration(c.InitialBackoffMs) * time.Millisecond << (n - 1)
+	if limit := time.Duration(c.MaxBackoffMs) * time.Millisecond; ceiling > limit || ceiling <= 0 {
+		ceiling = limit
+	}
+	return rand.N(ceiling) + 1
+}
+
+// latencyTracker keeps the latencies of the last successful calls.
+type latencyTracker struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (lt *latencyTracker) add(latency time.Duration) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	if len(lt.samples) < latencySamples {
+		lt.samples = append(lt.samples, latency)
+		return
+	}
+	lt.samples[lt.next] = latency
+	lt.next = (lt.next + 1) % latencySamples
+}
+
+// percentile returns false until enough calls were seen.
+func (lt *latencyTracker) percentile(p float64) (time.Duration, bool) {
+	lt.mu.Lock()
+	sorted := append([]time.Duration(nil), lt.samples...)
+	lt.mu.Unlock()
+
+	if len(sorted) < minLatencySamples {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(p*float64(len(sorted)-1))], true
+}
+
+// hedgeDelay is how long a call may take before a duplicate is sent.
+func (pc *ProviderClient) hedgeDelay() time.Duration {
+	hedge := pc.retry.Hedge
+	delay, ok := pc.latencies.percentile(hedge.Percentile)
+	if !ok {
+		delay = time.Duration(hedge.DelayMs) * time.Millisecond
+	}
+	return max(delay, time.Duration(hedge.MinDelayMs)*time.Millisecond)
+}
+
+type attemptResult struct {
+	entry *cache.Entry
+	err   error
+}
+
+// retryable reports transient provider failures. Errors raised by the
+// gateway itself and cancelled calls are final.
+func (r attemptResult) retryable() bool {
+	if r.err != nil {
+		return !throttled(r.err) && !errors.Is(r.err, context.Canceled) && !errors.Is(r.err, context.DeadlineExceeded)
+	}
+	switch r.entry.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// doWithRetries retries transient failures of idempotent calls with
+// jittered exponential backoff, as long as the request deadline leaves
+// room for another attempt.
+func (pc *ProviderClient) doWithRetries(req *http.Request, operation accounting.Operation) (*cache.Entry, error) {
+	ctx := req.Context()
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("error reading %s request: %v", pc.provider, err)
+		}
+		req.Body.Close()
+	}
+
+	var result attemptResult
+	for n := 0; n < pc.retry.MaxAttempts; n++ {
+		if n > 0 {
+			backoff := pc.retry.backoff(n)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff+minAttemptBudget {
+				break
+			}
+			log.Printf("[HOTEL-CLIENT] Retrying %s %s in %s (attempt %d): %s", pc.provider, operation, backoff.Round(time.Millisecond), n+1, result.reason())
+			if !sleep(ctx, backoff) {
+				break
+			}
+		}
+
+		result = pc.hedged(req, body, operation)
+		if !result.retryable() {
+			break
+		}
+	}
+	return result.entry, result.err
+}
+
+// hedged makes one attempt and, when hedging is on, duplicates of it while
+// it is slow. The first final answer wins and the others are cancelled.
+func (pc *ProviderClient) hedged(req *http.Request, body []byte, operation accounting.Operation) attemptResult {
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	results := make(chan attemptResult, 1+pc.retry.Hedge.MaxHedges)
+	launch := func() {
+		attempt := req.Clone(ctx)
+		if body != nil {
+			attempt.Body = io.NopCloser(bytes.NewReader(body))
+			attempt.ContentLength = int64(len(body))
+		}
+		go func() {
+			entry, err := pc.attempt(attempt, operation)
+			results <- attemptResult{entry: entry, err: err}
+		}()
+	}
+
+	launch()
+	if !pc.retry.Hedge.Enabled {
+		return <-results
+	}
+
+	launched, received := 1, 0
+	timer := time.NewTimer(pc.hedgeDelay())
+	defer timer.Stop()
+
+	var last attemptResult
+	for received < launched {
+		select {
+		case <-timer.C:
+			if launched <= pc.retry.Hedge.MaxHedges {
+				log.Printf("[HOTEL-CLIENT] Hedging slow %s %s (copy %d)", pc.provider, operation, launched)
+				launch()
+				launched++
+				timer.Reset(pc.hedgeDelay())
+			}
+		case result := <-results:
+			received++
+			if !result.retryable() {
+				return result
+			}
+			last = result
+		}
+	}
+	return last
+}
+
+func (r attemptResult) reason() string {
+	if r.err != nil {
+		return r.err.Error()
+	}
+	return fmt.Sprintf("status %d", r.entry.StatusCode)
+}
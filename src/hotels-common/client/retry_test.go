@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hotels-common/models"
+)
+
+func newRetryClient(t *testing.T, retry map[string]interface{}) *ProviderClient {
+	t.Helper()
+	retry["idempotent_paths"] = []string{"/search", "/book"}
+	pc, err := NewProviderClient(context.Background(), models.ProviderTBO, map[string]interface{}{
+		"retry":      retry,
+		"accounting": map[string]interface{}{"booking_paths": []string{"/book"}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return pc
+}
+
+func call(pc *ProviderClient, ctx context.Context, url string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(`{"CityCode":"150184"}`)).WithContext(ctx)
+	req.RequestURI = ""
+	rec := httptest.NewRecorder()
+	pc.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestProviderClient_RetriesTransientFailures(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer provider.Close()
+
+	pc := newRetryClient(t, map[string]interface{}{"max_attempts": 3, "initial_backoff_ms": 10, "max_backoff_ms": 20})
+
+	rec := call(pc, context.Background(), provider.URL+"/search")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "150184") {
+		t.Errorf("expected the retry to succeed with the original body, got %d %s", rec.Code, rec.Body.String())
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("expected 2 provider calls, got %d", got)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	if rec := call(pc, context.Background(), provider.URL+"/book"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected the booking failure to be returned as is, got %d", rec.Code)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected bookings never to be retried, got %d calls", got)
+	}
+}
+
+func TestProviderClient_RetriesWithinDeadline(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer provider.Close()
+
+	pc := newRetryClient(t, map[string]interface{}{"max_attempts": 5, "initial_backoff_ms": 1000, "max_backoff_ms": 1000})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if rec := call(pc, ctx, provider.URL+"/search"); rec.Code != http.StatusBadGateway {
+		t.Errorf("expected the provider failure, got %d", rec.Code)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected no retry past the deadline, got %d calls", got)
+	}
+}
+
+func TestProviderClient_HedgesSlowCalls(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// Cancelled once the hedge wins.
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"hedged":true}`))
+	}))
+	defer provider.Close()
+
+	pc := newRetryClient(t, map[string]interface{}{
+		"hedge": map[string]interface{}{"enabled": true, "delay_ms": 50, "min_delay_ms": 10},
+	})
+
+	start := time.Now()
+	rec := call(pc, context.Background(), provider.URL+"/search")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "hedged") {
+		t.Fatalf("expected the hedged answer, got %d %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the hedge to answer before the slow call, took %s", elapsed)
+	}
+}
+
+func TestProviderClient_CallsUnlistedPathsOnce(t *testing.T) {
+	var calls int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer provider.Close()
+
+	pc := newRetryClient(t, map[string]interface{}{
+		"max_attempts":       3,
+		"initial_backoff_ms": 10,
+		"max_backoff_ms":     20,
+		"hedge":              map[string]interface{}{"enabled": true, "delay_ms": 10, "min_delay_ms": 10},
+	})
+
+	if rec := call(pc, context.Background(), provider.URL+"/prebook"); rec.Code != http.StatusBadGateway {
+		t.Errorf("expected the provider failure, got %d", rec.Code)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("expected a POST to an unlisted path to be sent once, got %d calls", got)
+	}
+}
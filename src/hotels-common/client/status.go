@@ -0,0 +1,27 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// StatusHandler serves the circuit breaker states as JSON at path and
+// passes every other request to next. Provider plugins mount it as their
+// http-server handler for monitoring.
+func StatusHandler(path string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != path {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"circuit_breakers": BreakerStates(),
+		})
+	})
+}
@@ -1,11 +1,17 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"hotels-common/adapters"
+	"hotels-common/aggregation"
+	"hotels-common/degradation"
 	"hotels-common/models"
+	"hotels-common/pricecheck"
+	"hotels-common/rendering"
 	"hotels-common/transformers"
 	"log"
+	"redis"
 )
 
 type HotelPluginCore struct {
@@ -26,12 +32,20 @@ func (hpc *HotelPluginCore) RegisterTransformer(transformer transformers.HotelTr
 	hpc.registry.Register(transformer)
 }
 
-func (hpc *HotelPluginCore) ProcessResponse(input interface{}, config models.TransformationConfig) (interface{}, error) {
+func (hpc *HotelPluginCore) ProcessResponse(input interface{}, config models.TransformationConfig, renderer *rendering.Renderer) (interface{}, error) {
 	data, success := hpc.extractor.ExtractData(input)
 	if !success {
 		log.Printf("[HOTEL-CORE] Could not extract data, returning original input")
 		return input, nil
 	}
+	config.RequestContext = hpc.extractor.ExtractContext(input)
+
+	status, hasStatus := takeProviderStatus(data)
+	if hasStatus && !status.OK() {
+		log.Printf("[HOTEL-CORE] %s answered %s, returning no hotels", status.Provider, status.Status)
+		response := models.StandardResponse{Hotels: []models.BaseHotel{}, Providers: []models.ProviderStatus{status}}
+		return hpc.adapter.AdaptOutput(renderer.Render(response, hpc.selectProfile(input, renderer)), input), nil
+	}
 
 	var transformer transformers.HotelTransformer
 	var found bool
@@ -47,7 +61,7 @@ func (hpc *HotelPluginCore) ProcessResponse(input interface{}, config models.Tra
 	if !found {
 		log.Printf("[HOTEL-CORE] No transformer found, adding provider at root level")
 		data["provider"] = config.Provider
-		return hpc.adapter.AdaptOutput(models.StandardResponse{}, input), nil
+		return hpc.adapter.AdaptOutput(renderer.Render(models.StandardResponse{}, hpc.selectProfile(input, renderer)), input), nil
 	}
 
 	log.Printf("[HOTEL-CORE] Applying transformation using %s transformer", transformer.GetProvider())
@@ -55,23 +69,134 @@ func (hpc *HotelPluginCore) ProcessResponse(input interface{}, config models.Tra
 	if err != nil {
 		return nil, fmt.Errorf("error during transformation: %w", err)
 	}
+	if hasStatus {
+		standardResponse.Providers = []models.ProviderStatus{status}
+	}
+
+	return hpc.adapter.AdaptOutput(renderer.Render(standardResponse, hpc.selectProfile(input, renderer)), input), nil
+}
+
+// Aggregate merges the provider groups of an aggregated search. It answers
+// with whatever providers returned and fails only when none of them did.
+func (hpc *HotelPluginCore) Aggregate(input interface{}, groups map[string]models.Provider, renderer *rendering.Renderer) (interface{}, error) {
+	data, success := hpc.extractor.ExtractData(input)
+	if !success {
+		log.Printf("[HOTEL-CORE] Could not extract data, returning original input")
+		return input, nil
+	}
+
+	response := aggregation.Merge(data, groups)
+	if !aggregation.Succeeded(response) {
+		return nil, &aggregation.FailedError{Providers: response.Providers}
+	}
+	profile := hpc.selectProfile(input, renderer)
+	log.Printf("[HOTEL-CORE] Aggregated %d hotels from %d providers (profile %s)", len(response.Hotels), len(response.Providers), profile)
+
+	return hpc.adapter.AdaptOutput(renderer.Render(response, profile), input), nil
+}
+
+func (hpc *HotelPluginCore) CreateAggregationModifierFactory() func(map[string]interface{}) func(interface{}) (interface{}, error) {
+	return func(cfg map[string]interface{}) func(interface{}) (interface{}, error) {
+		groups := extractGroups(cfg)
+		outputProfile, _ := cfg["output_profile"].(string)
+		renderer := rendering.NewRenderer(outputProfile)
+
+		log.Printf("[HOTEL-CORE] Aggregation initialized with groups=%v, output_profile=%s", groups, outputProfile)
+
+		return func(input interface{}) (interface{}, error) {
+			return hpc.Aggregate(input, groups, renderer)
+		}
+	}
+}
+
+// takeProviderStatus removes the status the provider client added to the
+// response, if any, so transformers only see the provider data.
+func takeProviderStatus(data models.ResponseData) (models.ProviderStatus, bool) {
+	var status models.ProviderStatus
+	raw, exists := data[models.ProviderStatusKey]
+	if !exists {
+		return status, false
+	}
+	delete(data, models.ProviderStatusKey)
 
-	return hpc.adapter.AdaptOutput(standardResponse, input), nil
+	encoded, err := json.Marshal(raw)
+	if err != nil || json.Unmarshal(encoded, &status) != nil {
+		log.Printf("[HOTEL-CORE] Ignoring invalid provider status %v", raw)
+		return status, false
+	}
+	return status, true
+}
+
+func (hpc *HotelPluginCore) selectProfile(input interface{}, renderer *rendering.Renderer) string {
+	if request, ok := hpc.extractor.ExtractRequest(input); ok {
+		return renderer.Select(request.Headers())
+	}
+	return renderer.Select(nil)
 }
 
 func (hpc *HotelPluginCore) CreateModifierFactory(defaultProvider models.Provider) func(map[string]interface{}) func(interface{}) (interface{}, error) {
 	return func(cfg map[string]interface{}) func(interface{}) (interface{}, error) {
 		config := hpc.extractConfig(cfg, defaultProvider)
+		if hpc.usesStore(config.Provider) {
+			config.Store = hpc.openStore(config)
+			config.PriceChecks = hpc.newPriceCheckIssuer(config)
+		}
+		outputProfile, _ := cfg["output_profile"].(string)
+		renderer := rendering.NewRenderer(outputProfile)
 
-		log.Printf("[HOTEL-CORE] Plugin initialized with config: provider=%s, extra=%v",
-			config.Provider, config.ExtraConfig)
+		log.Printf("[HOTEL-CORE] Plugin initialized with config: provider=%s, output_profile=%s, extra=%v",
+			config.Provider, outputProfile, config.ExtraConfig)
 
 		return func(input interface{}) (interface{}, error) {
-			return hpc.ProcessResponse(input, config)
+			return hpc.ProcessResponse(input, config, renderer)
 		}
 	}
 }
 
+func (hpc *HotelPluginCore) usesStore(provider models.Provider) bool {
+	transformer, found := hpc.registry.Get(provider)
+	if !found {
+		return false
+	}
+	user, ok := transformer.(transformers.StoreUser)
+	return ok && user.UsesStore()
+}
+
+// openStore connects once per modifier instead of once per hotel. Modifiers
+// get no gateway context, so the pool is retained until the process shuts
+// down. A nil store means the plugin runs degraded.
+func (hpc *HotelPluginCore) openStore(config models.TransformationConfig) redis.Store {
+	redisConfig, err := redis.LoadConfig(config.ExtraConfig)
+	if err != nil {
+		log.Printf("[HOTEL-CORE] Error loading store config: %v", err)
+		return nil
+	}
+	store, err := redis.NewStore(redisConfig)
+	if err != nil {
+		degradation.Report("store", fmt.Sprintf("%s store unavailable: %v", config.Provider, err))
+		return nil
+	}
+	redis.Retain(redisConfig)
+	return store
+}
+
+// newPriceCheckIssuer falls back to the default config, without sealed
+// tokens, when the "price_check" object is invalid, so hotels get price
+// check keys whatever the config.
+func (hpc *HotelPluginCore) newPriceCheckIssuer(config models.TransformationConfig) *pricecheck.Issuer {
+	priceCheckConfig, err := pricecheck.LoadConfig(config.ExtraConfig)
+	if err != nil {
+		log.Printf("[HOTEL-CORE] Error loading price check config: %v", err)
+		priceCheckConfig = pricecheck.DefaultConfig()
+	}
+	issuer, err := pricecheck.NewIssuer(config.Store, priceCheckConfig)
+	if err != nil {
+		log.Printf("[HOTEL-CORE] %v", err)
+		issuer, _ = pricecheck.NewIssuer(config.Store, pricecheck.DefaultConfig())
+	}
+	return issuer
+}
+
 func (hpc *HotelPluginCore) extractConfig(cfg map[string]interface{}, defaultProvider models.Provider) models.TransformationConfig {
 	config := models.TransformationConfig{
 		Provider:    defaultProvider,
@@ -93,6 +218,18 @@ func (hpc *HotelPluginCore) extractConfig(cfg map[string]interface{}, defaultPro
 	return config
 }
 
+func extractGroups(cfg map[string]interface{}) map[string]models.Provider {
+	groups := make(map[string]models.Provider)
+	if rawGroups, ok := cfg["groups"].(map[string]interface{}); ok {
+		for group, provider := range rawGroups {
+			if providerStr, ok := provider.(string); ok {
+				groups[group] = models.Provider(providerStr)
+			}
+		}
+	}
+	return groups
+}
+
 func (hpc *HotelPluginCore) GetRegisteredProviders() []models.Provider {
 	providers := make([]models.Provider, 0)
 	for provider := range hpc.registry.GetAll() {
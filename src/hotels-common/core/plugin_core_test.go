@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hotels-common/client"
+	"hotels-common/models"
+	"hotels-common/rendering"
+)
+
+// stubTransformer turns {"hotels":[{"id":...}]} into hotels of provider.
+type stubTransformer struct {
+	provider models.Provider
+}
+
+func (st stubTransformer) Transform(data map[string]interface{}, config models.TransformationConfig) (models.StandardResponse, error) {
+	response := models.StandardResponse{Hotels: []models.BaseHotel{}}
+	hotels, _ := data["hotels"].([]interface{})
+	for _, hotel := range hotels {
+		fields, _ := hotel.(map[string]interface{})
+		response.Hotels = append(response.Hotels, models.BaseHotel{Provider: st.provider, ProductID: fields["id"]})
+	}
+	return response, nil
+}
+
+func (st stubTransformer) CanTransform(data map[string]interface{}) bool {
+	return false
+}
+
+func (st stubTransformer) GetProvider() models.Provider {
+	return st.provider
+}
+
+func (st stubTransformer) PriceCheckStrategy(data map[string]interface{}, config models.TransformationConfig) string {
+	return ""
+}
+
+// searchGroup calls the provider through its client and modifier, as the
+// backend of a group does.
+func searchGroup(t *testing.T, hpc *HotelPluginCore, provider models.Provider, url string) interface{} {
+	t.Helper()
+	pc, err := client.NewProviderClient(context.Background(), provider, map[string]interface{}{
+		"budget": map[string]interface{}{"timeout_ms": 100, "partial_results": true},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(`{"CityCode":"150184"}`))
+	req.RequestURI = ""
+	rec := httptest.NewRecorder()
+	pc.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s: expected the budget to answer 200, got %d", provider, rec.Code)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatalf("%s: expected a JSON body, got %q", provider, rec.Body.String())
+	}
+	output, err := hpc.ProcessResponse(data, models.TransformationConfig{Provider: provider}, rendering.NewRenderer(""))
+	if err != nil {
+		t.Fatalf("%s: expected no error, got %v", provider, err)
+	}
+	return output
+}
+
+func TestAggregate_KeepsProvidersWithinBudget(t *testing.T) {
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"hotels":[{"id":"1"},{"id":"2"}]}`))
+	}))
+	defer fast.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		w.Write([]byte(`{"hotels":[{"id":"3"}]}`))
+	}))
+	defer slow.Close()
+
+	hpc := NewHotelPluginCore()
+	hpc.RegisterTransformer(stubTransformer{provider: models.ProviderTBO})
+	hpc.RegisterTransformer(stubTransformer{provider: models.ProviderExpedia})
+
+	merged := map[string]interface{}{
+		"tbo_results":     searchGroup(t, hpc, models.ProviderTBO, fast.URL+"/search"),
+		"expedia_results": searchGroup(t, hpc, models.ProviderExpedia, slow.URL+"/search"),
+	}
+	output, err := hpc.Aggregate(merged, map[string]models.Provider{
+		"tbo_results":     models.ProviderTBO,
+		"expedia_results": models.ProviderExpedia,
+	}, rendering.NewRenderer(""))
+	if err != nil {
+		t.Fatalf("expected the TBO results, got %v", err)
+	}
+
+	var response models.StandardResponse
+	encoded, _ := json.Marshal(output)
+	json.Unmarshal(encoded, &response)
+
+	if len(response.Hotels) != 2 || response.Hotels[0].Provider != models.ProviderTBO {
+		t.Errorf("expected the 2 TBO hotels, got %+v", response.Hotels)
+	}
+	statuses := make(map[models.Provider]string)
+	for _, status := range response.Providers {
+		statuses[status.Provider] = status.Status
+	}
+	if statuses[models.ProviderTBO] != models.ProviderStatusOK || statuses[models.ProviderExpedia] != models.ProviderStatusTimeout {
+		t.Errorf("expected TBO ok and Expedia timed out, got %+v", response.Providers)
+	}
+}
+
+func TestNewPriceCheckIssuer_FallsBackOnInvalidConfig(t *testing.T) {
+	hpc := NewHotelPluginCore()
+	config := models.TransformationConfig{
+		Provider: models.ProviderTBO,
+		ExtraConfig: map[string]interface{}{
+			"price_check": map[string]interface{}{"secret_env": "MISSING_PRICE_CHECK_SECRET"},
+		},
+	}
+
+	issuer := hpc.newPriceCheckIssuer(config)
+	if issuer == nil {
+		t.Fatal("expected an issuer despite the missing secret")
+	}
+	if _, err := issuer.Issue(context.Background(), string(models.ProviderTBO), map[string]interface{}{"HotelCode": "1"}); err != nil {
+		t.Errorf("expected a price check key, got %v", err)
+	}
+}
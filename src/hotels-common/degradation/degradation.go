@@ -0,0 +1,38 @@
+package degradation
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// logInterval limits the degradation log to one line per component while
+// Redis stays down; Count keeps the exact number.
+const logInterval = time.Minute
+
+var (
+	mu       sync.Mutex
+	counts   = make(map[string]int64)
+	lastLogs = make(map[string]time.Time)
+)
+
+// Report records that component fell back to a degraded mode.
+func Report(component, reason string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	counts[component]++
+	now := time.Now()
+	if now.Sub(lastLogs[component]) < logInterval {
+		return
+	}
+	lastLogs[component] = now
+	log.Printf("[HOTEL-DEGRADED] %s: %s (%d times)", component, reason, counts[component])
+}
+
+// Count returns how many times component degraded since start.
+func Count(component string) int64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return counts[component]
+}
@@ -1,7 +1,16 @@
 module hotels-common
 
-go 1.23.0
-
-toolchain go1.25.3
+go 1.25.3
 
 require github.com/google/uuid v1.6.0
+
+require redis v0.0.0
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/klauspost/compress v1.20.1 // indirect
+	github.com/redis/go-redis/v9 v9.17.1 // indirect
+)
+
+replace redis => ../redis
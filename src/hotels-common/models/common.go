@@ -1,6 +1,12 @@
 package models
 
-import "time"
+import (
+	"context"
+	"time"
+
+	"hotels-common/pricecheck"
+	"redis"
+)
 
 type Provider string
 
@@ -9,8 +15,32 @@ const (
 	ProviderExpedia Provider = "Expedia"
 )
 
+const (
+	ProviderStatusOK      = "ok"
+	ProviderStatusTimeout = "timeout"
+	ProviderStatusError   = "error"
+
+	// ProviderStatusKey is the field the provider client adds to provider
+	// responses when partial results are enabled.
+	ProviderStatusKey = "providerStatus"
+)
+
 type StandardResponse struct {
 	Hotels []BaseHotel `json:"hotels"`
+	// Providers tells how each provider of the search answered. It is only
+	// set when partial results are enabled.
+	Providers []ProviderStatus `json:"providers,omitempty"`
+}
+
+type ProviderStatus struct {
+	Provider  Provider `json:"provider"`
+	Status    string   `json:"status"`
+	LatencyMs int64    `json:"latencyMs"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func (s ProviderStatus) OK() bool {
+	return s.Status == ProviderStatusOK
 }
 
 type BaseHotel struct {
@@ -56,6 +86,22 @@ type TransformationConfig struct {
 	StartDate   *time.Time             `json:"startDate,omitempty"`
 	EndDate     *time.Time             `json:"endDate,omitempty"`
 	ExtraConfig map[string]interface{} `json:"extraConfig,omitempty"`
+	// RequestContext is the context of the client request being transformed.
+	RequestContext context.Context `json:"-"`
+	// Store is opened once by the modifier factory. It is nil when the
+	// transformer does not use one or it could not be opened.
+	Store redis.Store `json:"-"`
+	// PriceChecks issues price check keys, degrading when Store is down.
+	PriceChecks *pricecheck.Issuer `json:"-"`
+}
+
+// Context returns the request context, or a background context outside a
+// request.
+func (c TransformationConfig) Context() context.Context {
+	if c.RequestContext != nil {
+		return c.RequestContext
+	}
+	return context.Background()
 }
 
 type ResponseData map[string]interface{}
@@ -0,0 +1,57 @@
+package pricecheck
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"redis"
+)
+
+type Config struct {
+	TTL int `json:"ttl"`
+	// The secret enables encrypted self-contained tokens when the store is
+	// unavailable. It is read from the environment or a mounted file.
+	SecretEnv  string `json:"secret_env"`
+	SecretFile string `json:"secret_file"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		TTL: redis.GetNamespace(redis.NamespacePriceCheck).TTL,
+	}
+}
+
+// LoadConfig reads the "price_check" object of the plugin configuration.
+func LoadConfig(extra map[string]interface{}) (*Config, error) {
+	config := DefaultConfig()
+
+	raw, exists := extra["price_check"]
+	if !exists {
+		return config, nil
+	}
+
+	configData, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling price check config: %v", err)
+	}
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling price check config: %v", err)
+	}
+
+	if config.TTL <= 0 {
+		config.TTL = redis.GetNamespace(redis.NamespacePriceCheck).TTL
+	}
+	return config, nil
+}
+
+// Secret returns the token secret, empty when none is configured.
+func (c *Config) Secret() ([]byte, error) {
+	if c.SecretEnv == "" && c.SecretFile == "" {
+		return nil, nil
+	}
+	secret, err := redis.ResolveSecret("", c.SecretEnv, c.SecretFile)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(secret), nil
+}
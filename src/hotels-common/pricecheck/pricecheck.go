@@ -0,0 +1,229 @@
+package pricecheck
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"hotels-common/degradation"
+	"redis"
+)
+
+const (
+	// sealedPrefix marks self-contained tokens; other keys are store ids.
+	sealedPrefix = "st."
+
+	component = "pricecheck"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid price check token")
+	ErrExpired      = errors.New("price check token expired")
+	ErrNotFound     = errors.New("price check not found")
+)
+
+type sealedPayload struct {
+	Provider  string          `json:"p"`
+	ExpiresAt int64           `json:"e"`
+	Data      json.RawMessage `json:"d"`
+}
+
+// Issuer hands out price check keys. It stores the payload in the store
+// and degrades, when the store is unavailable, to an encrypted
+// self-contained token or, without a secret, to an in-process store.
+type Issuer struct {
+	store    redis.Store
+	fallback redis.Store
+	aead     cipher.AEAD
+	ttl      int
+	now      func() time.Time
+}
+
+func NewIssuer(store redis.Store, config *Config) (*Issuer, error) {
+	secret, err := config.Secret()
+	if err != nil {
+		return nil, fmt.Errorf("error loading price check secret: %v", err)
+	}
+	issuer := &Issuer{
+		store:    store,
+		fallback: redis.NewMemoryStore(config.TTL),
+		ttl:      config.TTL,
+		now:      time.Now,
+	}
+	if len(secret) > 0 {
+		if issuer.aead, err = newAEAD(secret); err != nil {
+			return nil, fmt.Errorf("error creating price check cipher: %v", err)
+		}
+	}
+	return issuer, nil
+}
+
+// newAEAD derives the AES-256 key of the tokens from the secret, which may
+// have any length.
+func newAEAD(secret []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("price check token"))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (i *Issuer) Issue(ctx context.Context, provider string, data interface{}) (string, error) {
+	keys, errs := i.IssueBatch(ctx, provider, []interface{}{data})
+	return keys[0], errs[0]
+}
+
+// IssueBatch issues one key per snapshot, writing them to the store in a
+// single round trip. Snapshots the store rejects fall back individually.
+func (i *Issuer) IssueBatch(ctx context.Context, provider string, snapshots []interface{}) ([]string, []error) {
+	keys := make([]string, len(snapshots))
+	errs := make([]error, len(snapshots))
+	if len(snapshots) == 0 {
+		return keys, errs
+	}
+
+	stored := make(map[int]bool, len(snapshots))
+	if redis.IsHealthy(i.store) {
+		items := make([]redis.Item, len(snapshots))
+		for n, data := range snapshots {
+			keys[n] = redis.NewUUIDKey("")
+			items[n] = redis.Item{Key: storeKey(provider, keys[n]), Value: data, TTL: i.ttl}
+		}
+		failed := i.store.MSet(ctx, items)
+		if err := failed.Err(); err != nil {
+			degradation.Report(component, fmt.Sprintf("store write failed: %v", err))
+		}
+		for n, item := range items {
+			if _, ok := failed[item.Key]; !ok {
+				stored[n] = true
+			}
+		}
+	} else {
+		degradation.Report(component, "store unavailable")
+	}
+
+	for n, data := range snapshots {
+		if !stored[n] {
+			keys[n], errs[n] = i.degraded(ctx, provider, data)
+		}
+	}
+	return keys, errs
+}
+
+// degraded issues a sealed token, or an in-process key without a secret.
+func (i *Issuer) degraded(ctx context.Context, provider string, data interface{}) (string, error) {
+	if i.aead != nil {
+		return i.seal(provider, data)
+	}
+	id := redis.NewUUIDKey("")
+	if err := i.fallback.SetWithTTL(ctx, storeKey(provider, id), data, i.ttl); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Resolve returns the payload behind a key issued for provider.
+func (i *Issuer) Resolve(ctx context.Context, provider string, key string) (json.RawMessage, error) {
+	if strings.HasPrefix(key, sealedPrefix) {
+		return i.open(provider, key)
+	}
+
+	for _, store := range []redis.Store{i.store, i.fallback} {
+		if store == nil {
+			continue
+		}
+		data, err := store.Get(ctx, storeKey(provider, key))
+		if err == nil {
+			return json.RawMessage(data), nil
+		}
+		if !redis.IsNil(err) {
+			degradation.Report(component, fmt.Sprintf("store read failed: %v", err))
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// seal compresses and encrypts the payload so clients can neither read the
+// provider rates in it nor change them. The provider is authenticated too.
+func (i *Issuer) seal(provider string, data interface{}) (string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling price check: %v", err)
+	}
+	payload, err := json.Marshal(sealedPayload{
+		Provider:  provider,
+		ExpiresAt: i.now().Add(time.Duration(i.ttl) * time.Second).Unix(),
+		Data:      raw,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling price check: %v", err)
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(payload); err != nil {
+		return "", fmt.Errorf("error compressing price check: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("error compressing price check: %v", err)
+	}
+
+	nonce := make([]byte, i.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("error generating nonce: %v", err)
+	}
+	sealed := i.aead.Seal(nonce, nonce, compressed.Bytes(), []byte(provider))
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (i *Issuer) open(provider string, token string) (json.RawMessage, error) {
+	if i.aead == nil {
+		return nil, ErrInvalidToken
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, sealedPrefix))
+	if err != nil || len(sealed) < i.aead.NonceSize() {
+		return nil, ErrInvalidToken
+	}
+	nonce, ciphertext := sealed[:i.aead.NonceSize()], sealed[i.aead.NonceSize():]
+	compressed, err := i.aead.Open(nil, nonce, ciphertext, []byte(provider))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var opened sealedPayload
+	if err := json.Unmarshal(payload, &opened); err != nil || opened.Provider != provider {
+		return nil, ErrInvalidToken
+	}
+	if i.now().Unix() > opened.ExpiresAt {
+		return nil, ErrExpired
+	}
+	return opened.Data, nil
+}
+
+func storeKey(provider, id string) string {
+	return redis.GetNamespace(redis.NamespacePriceCheck).Key(strings.ToLower(provider), id)
+}
@@ -0,0 +1,69 @@
+package pricecheck
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"redis"
+)
+
+func TestIssuer_SealedTokenWhenStoreUnavailable(t *testing.T) {
+	t.Setenv("PRICE_CHECK_SECRET", "secret")
+	issuer, err := NewIssuer(nil, &Config{TTL: 60, SecretEnv: "PRICE_CHECK_SECRET"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	ctx := context.Background()
+
+	token, err := issuer.Issue(ctx, "Expedia", map[string]interface{}{"property_id": "123"})
+	if err != nil || !strings.HasPrefix(token, sealedPrefix) {
+		t.Fatalf("expected sealed token, got %q (%v)", token, err)
+	}
+	sealed, _ := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, sealedPrefix))
+	if strings.Contains(string(sealed), "property_id") {
+		t.Errorf("expected the payload to be encrypted, got %q", sealed)
+	}
+
+	payload, err := issuer.Resolve(ctx, "Expedia", token)
+	if err != nil || string(payload) != `{"property_id":"123"}` {
+		t.Errorf("expected payload to resolve, got %s (%v)", payload, err)
+	}
+	if _, err := issuer.Resolve(ctx, "TBO", token); err != ErrInvalidToken {
+		t.Errorf("expected token of another provider to be rejected, got %v", err)
+	}
+	if _, err := issuer.Resolve(ctx, "Expedia", token[:len(token)-2]+"xx"); err != ErrInvalidToken {
+		t.Errorf("expected tampered token to be rejected, got %v", err)
+	}
+
+	issuer.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := issuer.Resolve(ctx, "Expedia", token); err != ErrExpired {
+		t.Errorf("expected expired token, got %v", err)
+	}
+}
+
+func TestIssuer_MemoryFallbackWithoutSecret(t *testing.T) {
+	issuer, _ := NewIssuer(nil, DefaultConfig())
+	ctx := context.Background()
+
+	key, err := issuer.Issue(ctx, "Expedia", map[string]interface{}{"property_id": "123"})
+	if err != nil || strings.HasPrefix(key, sealedPrefix) {
+		t.Fatalf("expected in-memory key, got %q (%v)", key, err)
+	}
+	if _, err := issuer.Resolve(ctx, "Expedia", key); err != nil {
+		t.Errorf("expected key to resolve from memory, got %v", err)
+	}
+}
+
+func TestIssuer_UsesStore(t *testing.T) {
+	store := redis.NewMemoryStore(0)
+	issuer, _ := NewIssuer(store, DefaultConfig())
+	ctx := context.Background()
+
+	key, _ := issuer.Issue(ctx, "Expedia", "payload")
+	if _, err := store.Get(ctx, storeKey("Expedia", key)); err != nil {
+		t.Errorf("expected price check in the store, got %v", err)
+	}
+}
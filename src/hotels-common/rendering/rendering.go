@@ -0,0 +1,80 @@
+package rendering
+
+import (
+	"net/http"
+
+	"hotels-common/models"
+)
+
+const (
+	ProfileV1EN = "v1-en"
+	ProfileV2EN = "v2-en"
+
+	AcceptVersionHeader = "Accept-Version"
+)
+
+// Profile turns the canonical hotel model into the JSON shape a group of
+// consumers expects.
+type Profile func(response models.StandardResponse) map[string]interface{}
+
+type Renderer struct {
+	profiles       map[string]Profile
+	defaultProfile string
+}
+
+// NewRenderer registers the built-in profiles. An unknown defaultProfile
+// falls back to v1-en, the shape the hotel plugins have always returned.
+func NewRenderer(defaultProfile string) *Renderer {
+	r := &Renderer{
+		profiles: map[string]Profile{
+			ProfileV1EN: renderV1EN,
+			ProfileV2EN: renderV2EN,
+		},
+		defaultProfile: ProfileV1EN,
+	}
+	if _, exists := r.profiles[defaultProfile]; exists {
+		r.defaultProfile = defaultProfile
+	}
+	return r
+}
+
+// Select picks the profile requested through the Accept-Version header,
+// falling back to the configured default when it is missing or unknown.
+func (r *Renderer) Select(headers map[string][]string) string {
+	requested := http.Header(headers).Get(AcceptVersionHeader)
+	if _, exists := r.profiles[requested]; exists {
+		return requested
+	}
+	return r.defaultProfile
+}
+
+func (r *Renderer) Render(response models.StandardResponse, profile string) map[string]interface{} {
+	if render, exists := r.profiles[profile]; exists {
+		return render(response)
+	}
+	return r.profiles[r.defaultProfile](response)
+}
+
+// renderV1EN returns the hotels under "hotels". Before output profiles the
+// gateway response put them under a random numeric key, see CHANGELOG.md.
+func renderV1EN(response models.StandardResponse) map[string]interface{} {
+	return withProviders(map[string]interface{}{
+		"hotels": response.Hotels,
+	}, response)
+}
+
+// renderV2EN is the unified English schema shared with the car plugins.
+func renderV2EN(response models.StandardResponse) map[string]interface{} {
+	return withProviders(map[string]interface{}{
+		"version": ProfileV2EN,
+		"results": response.Hotels,
+	}, response)
+}
+
+// withProviders adds the provider statuses when partial results are on.
+func withProviders(output map[string]interface{}, response models.StandardResponse) map[string]interface{} {
+	if len(response.Providers) > 0 {
+		output["providers"] = response.Providers
+	}
+	return output
+}
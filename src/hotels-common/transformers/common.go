@@ -2,6 +2,7 @@ package transformers
 
 import (
 	"hotels-common/models"
+	"log"
 )
 
 type HotelTransformer interface {
@@ -11,6 +12,13 @@ type HotelTransformer interface {
 	PriceCheckStrategy(data map[string]interface{}, config models.TransformationConfig) string
 }
 
+// StoreUser is implemented by transformers that keep state, such as price
+// check payloads, in the key-value store. The modifier factory only opens a
+// store for them.
+type StoreUser interface {
+	UsesStore() bool
+}
+
 type BaseTransformer struct {
 	provider models.Provider
 }
@@ -75,3 +83,21 @@ func GetMapValue(m map[string]interface{}, key string) map[string]interface{} {
 	}
 	return nil
 }
+
+// AssignPriceCheckKeys stores one snapshot per hotel in a single batch and
+// sets the returned keys on the hotels. Hotels keep an empty key when price
+// checks are not configured or their snapshot could not be issued.
+func AssignPriceCheckKeys(config models.TransformationConfig, provider models.Provider, hotels []models.BaseHotel, snapshots []interface{}) {
+	if config.PriceChecks == nil || len(hotels) == 0 {
+		return
+	}
+
+	keys, errs := config.PriceChecks.IssueBatch(config.Context(), string(provider), snapshots)
+	for i := range hotels {
+		if errs[i] != nil {
+			log.Printf("[HOTEL-TRANSFORMER] Error issuing %s price check: %v", provider, errs[i])
+			continue
+		}
+		hotels[i].PriceCheckKey = keys[i]
+	}
+}
@@ -73,6 +73,11 @@ func (rp *TBORequestFactory) BuildRequest() func(map[string]interface{}) func(in
 			r.headers = http.Header{
 				"Content-Type": []string{"application/json"},
 			}
+			for _, name := range forwardedHeaders {
+				if value := http.Header(req.Headers()).Get(name); value != "" {
+					http.Header(r.headers).Set(name, value)
+				}
+			}
 			log.Printf("[TBO-PLUGIN] Sending request: %s", r.method)
 			log.Printf("[TBO-PLUGIN] Request body: %s", string(newBody))
 			return r, nil
@@ -94,6 +99,10 @@ func modifier(req IRequestWrapper) requestWrapper {
 
 var unknownTypeErr = errors.New("unknow request type")
 
+// forwardedHeaders survive the request rewrite so the provider client plugin
+// still sees them.
+var forwardedHeaders = []string{"X-Cache-Bypass"}
+
 type requestWrapper struct {
 	method  string
 	url     *url.URL
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Config struct {
+	Path string `json:"path"`
+	// AdminTenants may read every tenant's report; the others only their
+	// own.
+	AdminTenants []string `json:"admin_tenants"`
+	// MaxDays bounds the range of one report.
+	MaxDays int `json:"max_days"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Path:    "/__look-to-book",
+		MaxDays: 31,
+	}
+}
+
+func LoadConfig(extra map[string]interface{}) (*Config, error) {
+	config := DefaultConfig()
+
+	configData, err := json.Marshal(extra)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling config: %v", err)
+	}
+
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling config: %v", err)
+	}
+
+	if config.Path == "" {
+		config.Path = "/__look-to-book"
+	}
+	if config.MaxDays <= 0 {
+		config.MaxDays = 31
+	}
+
+	return config, nil
+}
+
+func (c *Config) admin(tenant string) bool {
+	for _, admin := range c.AdminTenants {
+		if admin == tenant {
+			return true
+		}
+	}
+	return false
+}
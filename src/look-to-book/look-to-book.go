@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"redis"
+)
+
+const PluginName = "look-to-book"
+
+func main() {}
+
+type handlerRegisterer string
+
+var HandlerRegisterer = handlerRegisterer(PluginName)
+
+func (hr handlerRegisterer) RegisterHandlers(f func(
+	name string,
+	handler func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error),
+)) {
+	f(string(hr), hr.registerHandlers)
+}
+
+// registerHandlers serves the look-to-book report and passes every other
+// request to the gateway. The report is scoped by the tenant api-key-auth
+// authenticated, so api-key-auth must come after it in the http-server names
+// and wrap it.
+func (hr handlerRegisterer) registerHandlers(ctx context.Context, extra map[string]interface{}, next http.Handler) (http.Handler, error) {
+	cfg, _ := extra[PluginName].(map[string]interface{})
+
+	config, err := LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading %s config: %v", PluginName, err)
+	}
+
+	redisConfig, err := redis.LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading Redis config: %v", err)
+	}
+
+	log.Printf("[LOOK-TO-BOOK] Serving the report at %s", config.Path)
+	store := redis.NewLazyStore("look-to-book", nil, redis.OpenUntilDone(ctx, redisConfig))
+	return NewReportHandler(config, store).Handler(next), nil
+}
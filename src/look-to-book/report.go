@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"hotels-common/accounting"
+	"redis"
+	"tenancy"
+)
+
+type Report struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Days has one row per day, tenant and provider; Totals sums them per
+	// tenant and provider over the range.
+	Days   []accounting.Usage `json:"days"`
+	Totals []accounting.Usage `json:"totals"`
+}
+
+// ReportHandler answers GET requests to the configured path with the
+// look-to-book report. Query parameters: from and to as YYYY-MM-DD,
+// defaulting to today, and tenant for admins.
+type ReportHandler struct {
+	config *Config
+	now    func() time.Time
+	store  *redis.LazyStore
+}
+
+func NewReportHandler(config *Config, store *redis.LazyStore) *ReportHandler {
+	return &ReportHandler{
+		config: config,
+		now:    time.Now,
+		store:  store,
+	}
+}
+
+func (rh *ReportHandler) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != rh.config.Path {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Only the tenant api-key-auth authenticated is trusted; the tenant
+		// header could come from the client.
+		identity, ok := tenancy.FromContext(req.Context())
+		if !ok || identity.Tenant == "" {
+			http.Error(w, "tenant required", http.StatusForbidden)
+			return
+		}
+		tenant := identity.Tenant
+		if rh.config.admin(identity.Tenant) {
+			tenant = req.URL.Query().Get("tenant")
+		}
+
+		from, to, err := rh.dateRange(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		store, err := rh.store.Get()
+		if err != nil {
+			log.Printf("[LOOK-TO-BOOK] %v", err)
+			http.Error(w, "report unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		days, err := accounting.Report(req.Context(), store, from, to, tenant)
+		if err != nil {
+			log.Printf("[LOOK-TO-BOOK] %v", err)
+			http.Error(w, "report unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(Report{
+			From:   accounting.Day(from),
+			To:     accounting.Day(to),
+			Days:   days,
+			Totals: totals(days),
+		})
+	})
+}
+
+func (rh *ReportHandler) dateRange(req *http.Request) (time.Time, time.Time, error) {
+	today, _ := accounting.ParseDay(accounting.Day(rh.now()))
+	from, to := today, today
+
+	query := req.URL.Query()
+	if value := query.Get("from"); value != "" {
+		day, err := accounting.ParseDay(value)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", value)
+		}
+		from = day
+	}
+	if value := query.Get("to"); value != "" {
+		day, err := accounting.ParseDay(value)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", value)
+		}
+		to = day
+	}
+
+	if to.Before(from) {
+		return from, to, fmt.Errorf("from must not be after to")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > rh.config.MaxDays {
+		return from, to, fmt.Errorf("range of %d days is over the maximum of %d", days, rh.config.MaxDays)
+	}
+	return from, to, nil
+}
+
+func totals(days []accounting.Usage) []accounting.Usage {
+	byKey := make(map[string]*accounting.Usage)
+	for _, day := range days {
+		key := day.Tenant + "|" + day.Provider
+		total, exists := byKey[key]
+		if !exists {
+			total = &accounting.Usage{Tenant: day.Tenant, Provider: day.Provider}
+			byKey[key] = total
+		}
+		total.Searches += day.Searches
+		total.PriceChecks += day.PriceChecks
+		total.Bookings += day.Bookings
+	}
+
+	result := make([]accounting.Usage, 0, len(byKey))
+	for _, total := range byKey {
+		total.LookToBook = accounting.Ratio(total.Searches, total.Bookings)
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Tenant != result[j].Tenant {
+			return result[i].Tenant < result[j].Tenant
+		}
+		return result[i].Provider < result[j].Provider
+	})
+	return result
+}
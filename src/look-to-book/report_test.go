@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hotels-common/accounting"
+	"redis"
+	"tenancy"
+)
+
+func newTestHandler(t *testing.T) *ReportHandler {
+	t.Helper()
+	store := redis.NewMemoryStore(0)
+	ledger := accounting.NewLedger(accounting.DefaultConfig())
+	for _, tenant := range []string{"acme", "acme", "globex"} {
+		ledger.Record(context.Background(), store, tenant, "TBO", accounting.OperationSearch)
+	}
+	ledger.Record(context.Background(), store, "acme", "TBO", accounting.OperationBooking)
+
+	config := DefaultConfig()
+	config.AdminTenants = []string{"ops"}
+	return NewReportHandler(config, redis.NewLazyStore("look-to-book", store, nil))
+}
+
+// get requests target authenticated as tenant, unauthenticated when tenant is
+// empty. headers are sent as is.
+func get(rh *ReportHandler, target, tenant string, headers ...string) (*httptest.ResponseRecorder, Report) {
+	handler := rh.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if tenant != "" {
+		req = req.WithContext(tenancy.NewContext(req.Context(), tenancy.Identity{Tenant: tenant}))
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var report Report
+	json.Unmarshal(rec.Body.Bytes(), &report)
+	return rec, report
+}
+
+func TestReportHandler_ScopesToTenant(t *testing.T) {
+	rh := newTestHandler(t)
+
+	rec, report := get(rh, "/__look-to-book?tenant=globex", "acme")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if len(report.Totals) != 1 || report.Totals[0].Tenant != "acme" || report.Totals[0].LookToBook != 2 {
+		t.Errorf("expected only acme with ratio 2, got %+v", report.Totals)
+	}
+
+	_, report = get(rh, "/__look-to-book", "ops")
+	if len(report.Totals) != 2 {
+		t.Errorf("expected admins to see every tenant, got %+v", report.Totals)
+	}
+}
+
+func TestReportHandler_Rejects(t *testing.T) {
+	rh := newTestHandler(t)
+	today := accounting.Day(time.Now())
+
+	for target, status := range map[string]int{
+		"/__look-to-book":                                  http.StatusForbidden,
+		"/__look-to-book?from=yesterday":                   http.StatusBadRequest,
+		"/__look-to-book?from=2026-01-01&to=2026-03-01":    http.StatusBadRequest,
+		"/__look-to-book?from=" + today + "&to=2020-01-01": http.StatusBadRequest,
+		"/products/hotels/availability":                    http.StatusTeapot,
+	} {
+		tenant := "acme"
+		if target == "/__look-to-book" {
+			tenant = ""
+		}
+		if rec, _ := get(rh, target, tenant); rec.Code != status {
+			t.Errorf("%s: expected %d, got %d", target, status, rec.Code)
+		}
+	}
+}
+
+func TestReportHandler_IgnoresTenantHeader(t *testing.T) {
+	rh := newTestHandler(t)
+
+	if rec, _ := get(rh, "/__look-to-book", "", "X-Tenant-ID", "ops"); rec.Code != http.StatusForbidden {
+		t.Errorf("expected an unauthenticated admin header to be rejected, got %d", rec.Code)
+	}
+
+	_, report := get(rh, "/__look-to-book", "acme", "X-Tenant-ID", "ops")
+	if len(report.Totals) != 1 || report.Totals[0].Tenant != "acme" {
+		t.Errorf("expected the report of the authenticated tenant, got %+v", report.Totals)
+	}
+}
@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Item is one value of a batch write. A TTL of 0 uses the configured
+// key_ttl.
+type Item struct {
+	Key   string
+	Value interface{}
+	TTL   int
+}
+
+// BatchErrors maps the keys that failed in a batch to their error.
+type BatchErrors map[string]error
+
+// Err summarizes the failed keys, nil when every key succeeded.
+func (be BatchErrors) Err() error {
+	if len(be) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(be))
+	for key := range be {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("%d keys failed: %s: %v", len(be), strings.Join(keys, ", "), be[keys[0]])
+}
+
+// Pipeline queues commands and sends them in one round trip on Exec. Keys
+// are prefixed and values encoded like the single-key methods.
+type Pipeline struct {
+	rc   *RedisClient
+	pipe redis.Pipeliner
+	keys []string
+	errs BatchErrors
+}
+
+func (rc *RedisClient) Pipeline() *Pipeline {
+	return &Pipeline{
+		rc:   rc,
+		pipe: rc.client.Pipeline(),
+		errs: make(BatchErrors),
+	}
+}
+
+func (p *Pipeline) Set(key string, value interface{}, ttl int) {
+	if ttl == 0 {
+		ttl = p.rc.config.KeyTTL
+	}
+	data, err := encodeValue(value)
+	if err == nil {
+		data, err = p.rc.codec.Encode(p.rc.config.KeyPrefix+key, data)
+	}
+	if err != nil {
+		p.errs[key] = err
+		return
+	}
+	p.pipe.Set(context.Background(), p.rc.config.KeyPrefix+key, data, time.Duration(ttl)*time.Second)
+	p.keys = append(p.keys, key)
+}
+
+func (p *Pipeline) Get(key string) {
+	p.pipe.Get(context.Background(), p.rc.config.KeyPrefix+key)
+	p.keys = append(p.keys, key)
+}
+
+func (p *Pipeline) Expire(key string, ttl int) {
+	p.pipe.Expire(context.Background(), p.rc.config.KeyPrefix+key, time.Duration(ttl)*time.Second)
+	p.keys = append(p.keys, key)
+}
+
+func (p *Pipeline) Delete(key string) {
+	p.pipe.Del(context.Background(), p.rc.config.KeyPrefix+key)
+	p.keys = append(p.keys, key)
+}
+
+// Exec sends the queued commands. Values of Get commands are returned by
+// key; missing keys are left out and are not errors.
+func (p *Pipeline) Exec(ctx context.Context) (map[string]string, BatchErrors) {
+	values := make(map[string]string)
+	if len(p.keys) == 0 {
+		return values, p.errs
+	}
+
+	// Exec returns the first failed command's error; each command keeps
+	// its own, which is what is reported.
+	cmds, _ := p.pipe.Exec(ctx)
+	for i, cmd := range cmds {
+		key := p.keys[i]
+		if err := cmd.Err(); err != nil {
+			if !IsNil(err) {
+				p.errs[key] = err
+			}
+			continue
+		}
+		get, ok := cmd.(*redis.StringCmd)
+		if !ok {
+			continue
+		}
+		data, err := p.rc.codec.Decode(p.rc.config.KeyPrefix+key, []byte(get.Val()))
+		if err != nil {
+			p.errs[key] = err
+			continue
+		}
+		values[key] = string(data)
+	}
+	return values, p.errs
+}
+
+// MSet writes every item in one round trip, each with its own TTL.
+func (rc *RedisClient) MSet(ctx context.Context, items []Item) BatchErrors {
+	pipe := rc.Pipeline()
+	for _, item := range items {
+		pipe.Set(item.Key, item.Value, item.TTL)
+	}
+	_, errs := pipe.Exec(ctx)
+	return errs
+}
+
+// MGet reads keys in one round trip. It pipelines GETs instead of MGET so
+// keys in different cluster slots work.
+func (rc *RedisClient) MGet(ctx context.Context, keys []string) (map[string]string, BatchErrors) {
+	pipe := rc.Pipeline()
+	for _, key := range keys {
+		pipe.Get(key)
+	}
+	return pipe.Exec(ctx)
+}
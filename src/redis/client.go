@@ -3,83 +3,237 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 var (
-	ctx           = context.Background()
 	clientOnce    sync.Once
 	clientManager *ClientManager
 )
 
 type ClientManager struct {
-	clients map[string]*redis.Client
+	clients map[string]*managedClient
 	mu      sync.RWMutex
+	stop    chan struct{}
+}
+
+// managedClient is a shared connection pool plus the state of its
+// background health check.
+type managedClient struct {
+	client  redis.UniversalClient
+	config  *Config
+	healthy atomic.Bool
 }
 
 func GetClientManager() *ClientManager {
 	clientOnce.Do(func() {
 		clientManager = &ClientManager{
-			clients: make(map[string]*redis.Client),
+			clients: make(map[string]*managedClient),
 		}
 	})
 	return clientManager
 }
 
-func (cm *ClientManager) GetOrCreateClient(config *Config) (*redis.Client, error) {
-	clientKey := fmt.Sprintf("%s:%d", config.RedisAddr, config.RedisDB)
+// GetOrCreateClient returns the pool for config, creating it on first use.
+// Existing pools are returned without a round trip; their health is checked
+// in the background instead.
+func (cm *ClientManager) GetOrCreateClient(config *Config) (redis.UniversalClient, error) {
+	managed, err := cm.getOrCreate(config)
+	if err != nil {
+		return nil, err
+	}
+	return managed.client, nil
+}
+
+func (cm *ClientManager) getOrCreate(config *Config) (*managedClient, error) {
+	clientKey := config.clientKey()
 
 	cm.mu.RLock()
-	client, exists := cm.clients[clientKey]
+	managed, exists := cm.clients[clientKey]
 	cm.mu.RUnlock()
-
-	if exists && client != nil {
-		if err := client.Ping(ctx).Err(); err == nil {
-			return client, nil
-		}
-		cm.mu.Lock()
-		delete(cm.clients, clientKey)
-		cm.mu.Unlock()
+	if exists {
+		return managed, nil
 	}
 
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	client = redis.NewClient(&redis.Options{
-		Addr:     config.RedisAddr,
-		Password: config.RedisPassword,
-		DB:       config.RedisDB,
-	})
+	if managed, exists := cm.clients[clientKey]; exists {
+		return managed, nil
+	}
+
+	client, err := newUniversalClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pingWithTimeout(client, config); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error connecting to Redis (%s) at %s: %v", config.RedisMode, strings.Join(config.Addrs(), ","), err)
+	}
+
+	managed = &managedClient{client: client, config: config}
+	managed.healthy.Store(true)
+	cm.clients[clientKey] = managed
+	cm.startHealthChecks(config.HealthCheckInterval())
+	closeOnShutdown()
+	fmt.Printf("Redis connection established: %s %s (DB: %d)\n", config.RedisMode, strings.Join(config.Addrs(), ","), config.RedisDB)
+
+	return managed, nil
+}
+
+// startHealthChecks pings every pool periodically and logs state changes.
+// Callers hold mu.
+func (cm *ClientManager) startHealthChecks(interval time.Duration) {
+	if cm.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	cm.stop = stop
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				cm.checkHealth()
+			}
+		}
+	}()
+}
+
+func (cm *ClientManager) checkHealth() {
+	cm.mu.RLock()
+	clients := make(map[string]*managedClient, len(cm.clients))
+	for key, managed := range cm.clients {
+		clients[key] = managed
+	}
+	cm.mu.RUnlock()
 
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("error connecting to Redis at %s: %v", config.RedisAddr, err)
+	for key, managed := range clients {
+		err := pingWithTimeout(managed.client, managed.config)
+		healthy := err == nil
+		if managed.healthy.Swap(healthy) != healthy {
+			if healthy {
+				fmt.Printf("Redis connection %s recovered\n", key)
+			} else {
+				fmt.Printf("Redis connection %s unhealthy: %v\n", key, err)
+			}
+		}
 	}
+}
 
-	cm.clients[clientKey] = client
-	fmt.Printf("Redis connection established: %s (DB: %d)\n", config.RedisAddr, config.RedisDB)
+func pingWithTimeout(client redis.UniversalClient, config *Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.DialTimeout())
+	defer cancel()
+	return client.Ping(ctx).Err()
+}
 
-	return client, nil
+// newUniversalClient builds the client for the configured mode. The mode is
+// explicit so a cluster with a single seed address is not mistaken for a
+// standalone server.
+func newUniversalClient(config *Config) (redis.UniversalClient, error) {
+	tlsConfig, err := config.RedisTLS.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.RedisMode {
+	case ModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    config.RedisMasterName,
+			SentinelAddrs: config.Addrs(),
+			Username:      config.RedisUsername,
+			Password:      config.RedisPassword,
+			DB:            config.RedisDB,
+			TLSConfig:     tlsConfig,
+			DialTimeout:   config.DialTimeout(),
+			ReadTimeout:   config.ReadTimeout(),
+			WriteTimeout:  config.WriteTimeout(),
+			PoolSize:      config.PoolSize,
+		}), nil
+	case ModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        config.Addrs(),
+			Username:     config.RedisUsername,
+			Password:     config.RedisPassword,
+			TLSConfig:    tlsConfig,
+			DialTimeout:  config.DialTimeout(),
+			ReadTimeout:  config.ReadTimeout(),
+			WriteTimeout: config.WriteTimeout(),
+			PoolSize:     config.PoolSize,
+		}), nil
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:         config.Addrs()[0],
+			Username:     config.RedisUsername,
+			Password:     config.RedisPassword,
+			DB:           config.RedisDB,
+			TLSConfig:    tlsConfig,
+			DialTimeout:  config.DialTimeout(),
+			ReadTimeout:  config.ReadTimeout(),
+			WriteTimeout: config.WriteTimeout(),
+			PoolSize:     config.PoolSize,
+		}), nil
+	}
+}
+
+// Close closes the pool of clientKey. Health checks stop with the last pool.
+func (cm *ClientManager) Close(clientKey string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	managed, exists := cm.clients[clientKey]
+	if !exists {
+		return
+	}
+	// Stores still holding the pool must not report it healthy.
+	managed.healthy.Store(false)
+	if err := managed.client.Close(); err != nil {
+		fmt.Printf("Error closing Redis connection %s: %v\n", clientKey, err)
+	}
+	delete(cm.clients, clientKey)
+
+	if len(cm.clients) == 0 && cm.stop != nil {
+		close(cm.stop)
+		cm.stop = nil
+	}
 }
 
 func (cm *ClientManager) CloseAll() {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	for key, client := range cm.clients {
-		if err := client.Close(); err != nil {
+	if cm.stop != nil {
+		close(cm.stop)
+		cm.stop = nil
+	}
+	for key, managed := range cm.clients {
+		managed.healthy.Store(false)
+		if err := managed.client.Close(); err != nil {
 			fmt.Printf("Error closing Redis connection %s: %v\n", key, err)
 		}
 	}
-	cm.clients = make(map[string]*redis.Client)
+	cm.clients = make(map[string]*managedClient)
 }
 
+var _ Store = (*RedisClient)(nil)
+
 type RedisClient struct {
-	client *redis.Client
-	config *Config
+	client  redis.UniversalClient
+	config  *Config
+	managed *managedClient
+	codec   *Codec
 }
 
 func NewRedisClient(config *Config) (*RedisClient, error) {
@@ -87,47 +241,64 @@ func NewRedisClient(config *Config) (*RedisClient, error) {
 		return nil, fmt.Errorf("invalid config: %v", err)
 	}
 
+	codec, err := NewCodec(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid codec config: %v", err)
+	}
+
 	cm := GetClientManager()
-	client, err := cm.GetOrCreateClient(config)
+	managed, err := cm.getOrCreate(config)
 	if err != nil {
 		return nil, err
 	}
 
 	return &RedisClient{
-		client: client,
-		config: config,
+		client:  managed.client,
+		config:  config,
+		managed: managed,
+		codec:   codec,
 	}, nil
 }
 
-func (rc *RedisClient) Set(key string, value interface{}) error {
-	var data []byte
-	var err error
+// Healthy reports the result of the last background health check.
+func (rc *RedisClient) Healthy() bool {
+	return rc.managed.healthy.Load()
+}
 
-	switch v := value.(type) {
-	case string:
-		data = []byte(v)
-	case []byte:
-		data = v
-	default:
-		data, err = json.Marshal(value)
-		if err != nil {
-			return fmt.Errorf("error marshaling value: %v", err)
-		}
+func (rc *RedisClient) Set(ctx context.Context, key string, value interface{}) error {
+	return rc.SetWithTTL(ctx, key, value, rc.config.KeyTTL)
+}
+
+func (rc *RedisClient) SetWithTTL(ctx context.Context, key string, value interface{}, ttl int) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
 	}
 
 	fullKey := rc.config.KeyPrefix + key
-	ttl := time.Duration(rc.config.KeyTTL) * time.Second
+	if data, err = rc.codec.Encode(fullKey, data); err != nil {
+		return err
+	}
+	duration := time.Duration(ttl) * time.Second
 
-	return rc.client.Set(ctx, fullKey, data, ttl).Err()
+	return rc.client.Set(ctx, fullKey, data, duration).Err()
 }
 
-func (rc *RedisClient) Get(key string) (string, error) {
+func (rc *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	fullKey := rc.config.KeyPrefix + key
-	return rc.client.Get(ctx, fullKey).Result()
+	data, err := rc.client.Get(ctx, fullKey).Bytes()
+	if err != nil {
+		return "", err
+	}
+	decoded, err := rc.codec.Decode(fullKey, data)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
 }
 
-func (rc *RedisClient) GetJSON(key string, dest interface{}) error {
-	data, err := rc.Get(key)
+func (rc *RedisClient) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	data, err := rc.Get(ctx, key)
 	if err != nil {
 		return err
 	}
@@ -135,41 +306,33 @@ func (rc *RedisClient) GetJSON(key string, dest interface{}) error {
 	return json.Unmarshal([]byte(data), dest)
 }
 
-func (rc *RedisClient) Delete(key string) error {
+func (rc *RedisClient) Delete(ctx context.Context, key string) error {
 	fullKey := rc.config.KeyPrefix + key
 	return rc.client.Del(ctx, fullKey).Err()
 }
 
-func (rc *RedisClient) Exists(key string) (bool, error) {
+func (rc *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
 	fullKey := rc.config.KeyPrefix + key
 	count, err := rc.client.Exists(ctx, fullKey).Result()
 	return count > 0, err
 }
 
-func (rc *RedisClient) Expire(key string, ttl int) error {
+func (rc *RedisClient) Expire(ctx context.Context, key string, ttl int) error {
 	fullKey := rc.config.KeyPrefix + key
 	return rc.client.Expire(ctx, fullKey, time.Duration(ttl)*time.Second).Err()
 }
 
-func (rc *RedisClient) Increment(key string) (int64, error) {
+func (rc *RedisClient) Increment(ctx context.Context, key string) (int64, error) {
 	fullKey := rc.config.KeyPrefix + key
 	return rc.client.Incr(ctx, fullKey).Result()
 }
 
-func (rc *RedisClient) SetNX(key string, value interface{}, ttl int) (bool, error) {
-	var data []byte
-	var err error
-
-	switch v := value.(type) {
-	case string:
-		data = []byte(v)
-	case []byte:
-		data = v
-	default:
-		data, err = json.Marshal(value)
-		if err != nil {
-			return false, fmt.Errorf("error marshaling value: %v", err)
-		}
+// SetNX stores value as is, without the codec, so locks and markers can be
+// compared server side.
+func (rc *RedisClient) SetNX(ctx context.Context, key string, value interface{}, ttl int) (bool, error) {
+	data, err := encodeValue(value)
+	if err != nil {
+		return false, err
 	}
 
 	fullKey := rc.config.KeyPrefix + key
@@ -178,26 +341,68 @@ func (rc *RedisClient) SetNX(key string, value interface{}, ttl int) (bool, erro
 	return rc.client.SetNX(ctx, fullKey, data, duration).Result()
 }
 
-func (rc *RedisClient) Keys(pattern string) ([]string, error) {
-	fullPattern := rc.config.KeyPrefix + pattern
-	keys, err := rc.client.Keys(ctx, fullPattern).Result()
-	if err != nil {
-		return nil, err
-	}
+var (
+	compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+	compareAndExpireScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("EXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+)
 
-	prefixLen := len(rc.config.KeyPrefix)
-	cleanKeys := make([]string, len(keys))
-	for i, key := range keys {
-		cleanKeys[i] = key[prefixLen:]
-	}
+func (rc *RedisClient) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	fullKey := rc.config.KeyPrefix + key
+	n, err := compareAndDeleteScript.Run(ctx, rc.client, []string{fullKey}, value).Int()
+	return n == 1, err
+}
 
-	return cleanKeys, nil
+func (rc *RedisClient) CompareAndExpire(ctx context.Context, key string, value string, ttl int) (bool, error) {
+	fullKey := rc.config.KeyPrefix + key
+	n, err := compareAndExpireScript.Run(ctx, rc.client, []string{fullKey}, value, ttl).Int()
+	return n == 1, err
 }
 
-func (rc *RedisClient) Ping() error {
+// Keys collects the keys matching pattern. It iterates with SCAN, so it is
+// safe on a shared server but still loads every key in memory; prefer Scan
+// for large key spaces.
+func (rc *RedisClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	keys := make([]string, 0)
+	it := rc.Scan(ctx, pattern, 0)
+	for it.Next(ctx) {
+		keys = append(keys, it.Key())
+	}
+	return keys, it.Err()
+}
+
+func (rc *RedisClient) Ping(ctx context.Context) error {
 	return rc.client.Ping(ctx).Err()
 }
 
-func (rc *RedisClient) GetClient() *redis.Client {
+func (rc *RedisClient) GetClient() redis.UniversalClient {
 	return rc.client
 }
+
+func encodeValue(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling value: %v", err)
+		}
+		return data, nil
+	}
+}
+
+// IsNil reports whether err means the key does not exist.
+func IsNil(err error) bool {
+	return errors.Is(err, redis.Nil)
+}
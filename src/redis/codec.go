@@ -0,0 +1,266 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// Encoded values start with a marker that JSON and plain strings never
+// start with, so values written before codecs were enabled can still be
+// read as they are.
+var codecMagic = []byte{0x00, 'K', 'V', 1}
+
+const (
+	flagGzip byte = 1 << iota
+	flagZstd
+	flagEncrypted
+)
+
+// EncryptionConfig enables AES-GCM envelope encryption. New values are
+// sealed with ActiveKey; the other keys are kept to read values written
+// before a rotation. Unencrypted values are refused unless AllowPlaintext
+// is set while migrating existing data.
+type EncryptionConfig struct {
+	Enabled        bool            `json:"enabled"`
+	ActiveKey      string          `json:"active_key"`
+	Keys           []EncryptionKey `json:"keys"`
+	AllowPlaintext bool            `json:"allow_plaintext"`
+}
+
+// EncryptionKey is a base64 encoded 16, 24 or 32 byte key, read from the
+// environment or a mounted secret file.
+type EncryptionKey struct {
+	ID   string `json:"id"`
+	Env  string `json:"env"`
+	File string `json:"file"`
+}
+
+// Codec compresses and encrypts values on write and reverses it on read.
+type Codec struct {
+	compression    string
+	threshold      int
+	activeKey      string
+	ciphers        map[string]cipher.AEAD
+	allowPlaintext bool
+}
+
+// The zstd encoder and decoder are safe for concurrent EncodeAll/DecodeAll
+// calls and costly to create, so they are shared.
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+func zstdCodecs() (*zstd.Encoder, *zstd.Decoder, error) {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdEncoder, zstdDecoder, zstdErr
+}
+
+func NewCodec(config *Config) (*Codec, error) {
+	codec := &Codec{
+		compression: config.Compression,
+		threshold:   config.CompressionThreshold,
+		ciphers:     make(map[string]cipher.AEAD),
+		// Without encryption every value is plaintext.
+		allowPlaintext: true,
+	}
+
+	switch codec.compression {
+	case CompressionNone, CompressionGzip, CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unknown compression %q, expected %s or %s", codec.compression, CompressionGzip, CompressionZstd)
+	}
+
+	encryption := config.Encryption
+	if encryption == nil || !encryption.Enabled {
+		return codec, nil
+	}
+
+	for _, key := range encryption.Keys {
+		secret, err := ResolveSecret("", key.Env, key.File)
+		if err != nil {
+			return nil, fmt.Errorf("error loading encryption key %s: %v", key.ID, err)
+		}
+		raw, err := base64.StdEncoding.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s is not valid base64: %v", key.ID, err)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %v", key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %s: %v", key.ID, err)
+		}
+		codec.ciphers[key.ID] = aead
+	}
+
+	if _, exists := codec.ciphers[encryption.ActiveKey]; !exists {
+		return nil, fmt.Errorf("active encryption key %q is not configured", encryption.ActiveKey)
+	}
+	// Encode writes the length of the key ID in one byte.
+	if len(encryption.ActiveKey) > 255 {
+		return nil, fmt.Errorf("active encryption key ID must be at most 255 bytes")
+	}
+	codec.activeKey = encryption.ActiveKey
+	codec.allowPlaintext = encryption.AllowPlaintext
+	return codec, nil
+}
+
+// Enabled reports whether Encode changes values at all.
+func (c *Codec) Enabled() bool {
+	return c.compression != CompressionNone || c.activeKey != ""
+}
+
+// Encode compresses data above the threshold and encrypts it. The key is
+// authenticated with the value so a value copied under another key fails
+// to decrypt.
+func (c *Codec) Encode(key string, data []byte) ([]byte, error) {
+	if !c.Enabled() {
+		return data, nil
+	}
+
+	var flags byte
+	payload := data
+	if c.compression != CompressionNone && len(data) >= c.threshold {
+		compressed, flag, err := c.compress(data)
+		if err != nil {
+			return nil, err
+		}
+		payload, flags = compressed, flags|flag
+	}
+
+	var header bytes.Buffer
+	header.Write(codecMagic)
+
+	if c.activeKey == "" {
+		header.WriteByte(flags)
+		return append(header.Bytes(), payload...), nil
+	}
+
+	aead := c.ciphers[c.activeKey]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %v", err)
+	}
+
+	header.WriteByte(flags | flagEncrypted)
+	header.WriteByte(byte(len(c.activeKey)))
+	header.WriteString(c.activeKey)
+	header.Write(nonce)
+	return aead.Seal(header.Bytes(), nonce, payload, []byte(key)), nil
+}
+
+// Decode reverses Encode. Values without the codec marker are returned
+// unchanged. With encryption they are refused, like values not encrypted,
+// unless plaintext is allowed; counters kept by Increment are the exception
+// since Redis writes them itself.
+func (c *Codec) Decode(key string, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, codecMagic) || len(data) <= len(codecMagic) {
+		if !c.allowPlaintext && !isCounter(data) {
+			return nil, fmt.Errorf("value %s is not encrypted", key)
+		}
+		return data, nil
+	}
+
+	flags := data[len(codecMagic)]
+	payload := data[len(codecMagic)+1:]
+	if flags&flagEncrypted == 0 && !c.allowPlaintext {
+		return nil, fmt.Errorf("value %s is not encrypted", key)
+	}
+
+	if flags&flagEncrypted != 0 {
+		if len(payload) == 0 {
+			return nil, fmt.Errorf("corrupted value %s", key)
+		}
+		idLen := int(payload[0])
+		if len(payload) < 1+idLen {
+			return nil, fmt.Errorf("corrupted value %s", key)
+		}
+		keyID := string(payload[1 : 1+idLen])
+		aead, exists := c.ciphers[keyID]
+		if !exists {
+			return nil, fmt.Errorf("value %s is encrypted with unknown key %q", key, keyID)
+		}
+		rest := payload[1+idLen:]
+		if len(rest) < aead.NonceSize() {
+			return nil, fmt.Errorf("corrupted value %s", key)
+		}
+		plain, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting %s: %v", key, err)
+		}
+		payload = plain
+	}
+
+	switch {
+	case flags&flagGzip != 0:
+		reader, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing %s: %v", key, err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	case flags&flagZstd != 0:
+		_, decoder, err := zstdCodecs()
+		if err != nil {
+			return nil, err
+		}
+		plain, err := decoder.DecodeAll(payload, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error decompressing %s: %v", key, err)
+		}
+		return plain, nil
+	}
+	return payload, nil
+}
+
+func isCounter(data []byte) bool {
+	_, err := strconv.ParseInt(string(data), 10, 64)
+	return err == nil
+}
+
+func (c *Codec) compress(data []byte) ([]byte, byte, error) {
+	switch c.compression {
+	case CompressionZstd:
+		encoder, _, err := zstdCodecs()
+		if err != nil {
+			return nil, 0, err
+		}
+		return encoder.EncodeAll(data, nil), flagZstd, nil
+	default:
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return nil, 0, fmt.Errorf("error compressing value: %v", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, 0, fmt.Errorf("error compressing value: %v", err)
+		}
+		return buf.Bytes(), flagGzip, nil
+	}
+}
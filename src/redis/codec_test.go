@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testCodec(t *testing.T, compression string, keys map[string]string, active string) *Codec {
+	config := DefaultConfig()
+	config.Compression = compression
+	config.CompressionThreshold = 16
+	if active != "" {
+		config.Encryption = &EncryptionConfig{Enabled: true, ActiveKey: active}
+		for id, env := range keys {
+			config.Encryption.Keys = append(config.Encryption.Keys, EncryptionKey{ID: id, Env: env})
+		}
+	}
+	codec, err := NewCodec(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return codec
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	t.Setenv("REDIS_KEY_2025", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	value := []byte(`{"hotel":"` + strings.Repeat("a", 200) + `"}`)
+
+	for _, compression := range []string{CompressionNone, CompressionGzip, CompressionZstd} {
+		codec := testCodec(t, compression, map[string]string{"2025": "REDIS_KEY_2025"}, "2025")
+		encoded, err := codec.Encode("k", value)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", compression, err)
+		}
+		if bytes.Contains(encoded, []byte("hotel")) {
+			t.Errorf("%s: expected value to be encrypted", compression)
+		}
+		decoded, err := codec.Decode("k", encoded)
+		if err != nil || !bytes.Equal(decoded, value) {
+			t.Errorf("%s: expected round trip, got %q (%v)", compression, decoded, err)
+		}
+		if _, err := codec.Decode("other", encoded); err == nil {
+			t.Errorf("%s: expected value moved to another key to fail", compression)
+		}
+	}
+}
+
+func TestCodec_KeyRotationAndLegacyValues(t *testing.T) {
+	t.Setenv("REDIS_KEY_OLD", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	t.Setenv("REDIS_KEY_NEW", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32)))
+	keys := map[string]string{"old": "REDIS_KEY_OLD", "new": "REDIS_KEY_NEW"}
+
+	encoded, _ := testCodec(t, CompressionNone, keys, "old").Encode("k", []byte("secret"))
+	rotated := testCodec(t, CompressionGzip, keys, "new")
+	if decoded, err := rotated.Decode("k", encoded); err != nil || string(decoded) != "secret" {
+		t.Errorf("expected value sealed with the old key to decode, got %q (%v)", decoded, err)
+	}
+	if _, err := rotated.Decode("k", []byte(`{"plain":true}`)); err == nil {
+		t.Error("expected a plaintext value to be refused")
+	}
+
+	config := DefaultConfig()
+	config.Encryption = &EncryptionConfig{Enabled: true, ActiveKey: "new", AllowPlaintext: true,
+		Keys: []EncryptionKey{{ID: "new", Env: "REDIS_KEY_NEW"}}}
+	migrating, err := NewCodec(config)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decoded, err := migrating.Decode("k", []byte(`{"plain":true}`)); err != nil || string(decoded) != `{"plain":true}` {
+		t.Errorf("expected legacy value to pass through while migrating, got %q (%v)", decoded, err)
+	}
+}
+
+func TestCodec_RefusesUnencryptedValues(t *testing.T) {
+	t.Setenv("REDIS_KEY_2025", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	codec := testCodec(t, CompressionGzip, map[string]string{"2025": "REDIS_KEY_2025"}, "2025")
+
+	compressed, _ := testCodec(t, CompressionGzip, nil, "").Encode("k", bytes.Repeat([]byte("a"), 64))
+	if _, err := codec.Decode("k", compressed); err == nil {
+		t.Error("expected a value with the marker but not encrypted to be refused")
+	}
+	if decoded, err := codec.Decode("k", []byte("42")); err != nil || string(decoded) != "42" {
+		t.Errorf("expected counters to be read, got %q (%v)", decoded, err)
+	}
+}
+
+func TestNewCodec_RejectsLongKeyID(t *testing.T) {
+	t.Setenv("REDIS_KEY_LONG", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	id := strings.Repeat("k", 256)
+	config := DefaultConfig()
+	config.Encryption = &EncryptionConfig{Enabled: true, ActiveKey: id, Keys: []EncryptionKey{{ID: id, Env: "REDIS_KEY_LONG"}}}
+	if _, err := NewCodec(config); err == nil {
+		t.Error("expected a key ID over 255 bytes to be rejected")
+	}
+}
+
+func TestCodec_SmallValuesAreNotCompressed(t *testing.T) {
+	codec := testCodec(t, CompressionGzip, nil, "")
+	encoded, _ := codec.Encode("k", []byte("tiny"))
+	if encoded[len(codecMagic)]&flagGzip != 0 {
+		t.Error("expected values under the threshold to stay uncompressed")
+	}
+}
@@ -1,25 +1,74 @@
 package redis
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
 )
 
 type Config struct {
-	RedisAddr     string `json:"redis_addr"`
-	RedisPassword string `json:"redis_password"`
-	RedisDB       int    `json:"redis_db"`
-	KeyPrefix     string `json:"key_prefix"`
-	KeyTTL        int    `json:"key_ttl"`
+	// Store selects the backend: "redis" (default) or "memory".
+	Store           string   `json:"store"`
+	RedisMode       string   `json:"redis_mode"`
+	RedisAddr       string   `json:"redis_addr"`
+	RedisAddrs      []string `json:"redis_addrs"`
+	RedisMasterName string   `json:"redis_master_name"`
+	RedisUsername   string   `json:"redis_username"`
+	RedisPassword   string   `json:"redis_password"`
+	RedisDB         int      `json:"redis_db"`
+	KeyPrefix       string   `json:"key_prefix"`
+	KeyTTL          int      `json:"key_ttl"`
+
+	// Timeouts in milliseconds. Every operation is also bounded by the
+	// caller's context.
+	DialTimeoutMs  int `json:"dial_timeout_ms"`
+	ReadTimeoutMs  int `json:"read_timeout_ms"`
+	WriteTimeoutMs int `json:"write_timeout_ms"`
+	PoolSize       int `json:"pool_size"`
+
+	HealthCheckIntervalMs int `json:"health_check_interval_ms"`
+
+	// Values written with Set are compressed above CompressionThreshold
+	// bytes and optionally encrypted; see Codec.
+	Compression          string            `json:"compression"`
+	CompressionThreshold int               `json:"compression_threshold"`
+	Encryption           *EncryptionConfig `json:"encryption"`
+
+	// Credentials can come from the environment or a mounted secret file
+	// instead of the gateway configuration.
+	RedisUsernameEnv  string     `json:"redis_username_env"`
+	RedisUsernameFile string     `json:"redis_username_file"`
+	RedisPasswordEnv  string     `json:"redis_password_env"`
+	RedisPasswordFile string     `json:"redis_password_file"`
+	RedisTLS          *TLSConfig `json:"redis_tls"`
 }
 
 func DefaultConfig() *Config {
 	return &Config{
+		Store:         StoreRedis,
+		RedisMode:     ModeStandalone,
 		RedisAddr:     "redis:6379",
 		RedisPassword: "",
 		RedisDB:       0,
 		KeyPrefix:     "krakend:",
 		KeyTTL:        3600,
+
+		DialTimeoutMs:  2000,
+		ReadTimeoutMs:  500,
+		WriteTimeoutMs: 500,
+
+		HealthCheckIntervalMs: 10000,
+
+		CompressionThreshold: 1024,
 	}
 }
 
@@ -35,7 +84,18 @@ func LoadConfig(extra map[string]interface{}) (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %v", err)
 	}
 
-	if config.RedisAddr == "" {
+	if config.RedisUsername, err = ResolveSecret(config.RedisUsername, config.RedisUsernameEnv, config.RedisUsernameFile); err != nil {
+		return nil, fmt.Errorf("error loading redis username: %v", err)
+	}
+	if config.RedisPassword, err = ResolveSecret(config.RedisPassword, config.RedisPasswordEnv, config.RedisPasswordFile); err != nil {
+		return nil, fmt.Errorf("error loading redis password: %v", err)
+	}
+
+	if config.RedisMode == "" {
+		config.RedisMode = ModeStandalone
+	}
+	config.RedisMode = strings.ToLower(config.RedisMode)
+	if config.RedisAddr == "" && len(config.RedisAddrs) == 0 {
 		config.RedisAddr = "localhost:6379"
 	}
 	if config.KeyPrefix == "" {
@@ -48,15 +108,82 @@ func LoadConfig(extra map[string]interface{}) (*Config, error) {
 	return config, nil
 }
 
+func (c *Config) DialTimeout() time.Duration {
+	return time.Duration(c.DialTimeoutMs) * time.Millisecond
+}
+
+func (c *Config) ReadTimeout() time.Duration {
+	return time.Duration(c.ReadTimeoutMs) * time.Millisecond
+}
+
+func (c *Config) WriteTimeout() time.Duration {
+	return time.Duration(c.WriteTimeoutMs) * time.Millisecond
+}
+
+func (c *Config) HealthCheckInterval() time.Duration {
+	if c.HealthCheckIntervalMs <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.HealthCheckIntervalMs) * time.Millisecond
+}
+
+// Addrs returns redis_addrs, falling back to the single redis_addr.
+func (c *Config) Addrs() []string {
+	if len(c.RedisAddrs) > 0 {
+		return c.RedisAddrs
+	}
+	if c.RedisAddr != "" {
+		return []string{c.RedisAddr}
+	}
+	return nil
+}
+
+// clientKey identifies the pool shared by every config for the same server
+// and credentials. The password and TLS settings are hashed so the key can
+// be logged.
+func (c *Config) clientKey() string {
+	tlsConfig, _ := json.Marshal(c.RedisTLS)
+	secrets := sha256.Sum256([]byte(c.RedisPassword + "\x00" + string(tlsConfig)))
+	return fmt.Sprintf("%s:%s:%s:%s:%d:%s", c.RedisMode, strings.Join(c.Addrs(), ","), c.RedisMasterName, c.RedisUsername, c.RedisDB,
+		hex.EncodeToString(secrets[:8]))
+}
+
 func (c *Config) Validate() error {
-	if c.RedisAddr == "" {
-		return fmt.Errorf("redis_addr is required")
+	if len(c.Addrs()) == 0 {
+		return fmt.Errorf("redis_addr or redis_addrs is required")
 	}
 	if c.KeyTTL < 0 {
 		return fmt.Errorf("key_ttl must be greater than or equal to 0")
 	}
-	if c.RedisDB < 0 || c.RedisDB > 15 {
-		return fmt.Errorf("redis_db must be between 0 and 15")
+	if c.DialTimeoutMs <= 0 || c.ReadTimeoutMs <= 0 || c.WriteTimeoutMs <= 0 {
+		return fmt.Errorf("dial_timeout_ms, read_timeout_ms and write_timeout_ms must be greater than 0")
+	}
+	if c.PoolSize < 0 {
+		return fmt.Errorf("pool_size must be greater than or equal to 0")
+	}
+	if err := c.RedisTLS.Validate(); err != nil {
+		return err
+	}
+
+	switch c.RedisMode {
+	case "", ModeStandalone:
+		if c.RedisDB < 0 || c.RedisDB > 15 {
+			return fmt.Errorf("redis_db must be between 0 and 15")
+		}
+	case ModeSentinel:
+		if c.RedisMasterName == "" {
+			return fmt.Errorf("redis_master_name is required in sentinel mode")
+		}
+		if c.RedisDB < 0 || c.RedisDB > 15 {
+			return fmt.Errorf("redis_db must be between 0 and 15")
+		}
+	case ModeCluster:
+		// Cluster mode only has database 0, so redis_db is not used.
+		if c.RedisDB != 0 {
+			fmt.Printf("Redis redis_db %d ignored in cluster mode\n", c.RedisDB)
+		}
+	default:
+		return fmt.Errorf("redis_mode must be one of %s, %s or %s", ModeStandalone, ModeSentinel, ModeCluster)
 	}
 	return nil
 }
@@ -0,0 +1,41 @@
+package redis
+
+import "testing"
+
+func TestConfig_ClusterIgnoresDatabase(t *testing.T) {
+	config, err := LoadConfig(map[string]interface{}{
+		"redis_mode":  "cluster",
+		"redis_addrs": []interface{}{"redis-1:6379", "redis-2:6379"},
+		"redis_db":    20,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("expected redis_db to be ignored in cluster mode, got %v", err)
+	}
+
+	config.RedisMode = ModeStandalone
+	if err := config.Validate(); err == nil {
+		t.Error("expected redis_db 20 to be rejected in standalone mode")
+	}
+}
+
+func TestConfig_ClientKeySeparatesCredentials(t *testing.T) {
+	config := DefaultConfig()
+	other := DefaultConfig()
+	if config.clientKey() != other.clientKey() {
+		t.Fatal("expected identical configs to share a pool")
+	}
+
+	other.RedisPassword = "secret"
+	if config.clientKey() == other.clientKey() {
+		t.Error("expected another password to get its own pool")
+	}
+
+	other = DefaultConfig()
+	other.RedisTLS = &TLSConfig{Enabled: true}
+	if config.clientKey() == other.clientKey() {
+		t.Error("expected TLS settings to get their own pool")
+	}
+}
@@ -2,8 +2,13 @@ module redis
 
 go 1.25.3
 
-require github.com/google/uuid v1.6.0
+require (
+	github.com/google/uuid v1.6.0
+	github.com/klauspost/compress v1.20.1
+	github.com/redis/go-redis/v9 v9.17.1
+)
 
 require (
-	github.com/redis/go-redis/v9 v9.17.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 )
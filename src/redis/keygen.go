@@ -1,8 +1,12 @@
 package redis
 
 import (
+	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -77,6 +81,48 @@ func (kg *CompositeKeyGenerator) Generate() string {
 	return result
 }
 
+// HashKeyGenerator derives the key from the content of Value, so the same
+// logical object always maps to the same key. Length truncates the hex
+// digest; 0 keeps all 64 characters.
+type HashKeyGenerator struct {
+	Prefix string
+	Value  interface{}
+	Length int
+}
+
+func (kg *HashKeyGenerator) Generate() string {
+	data, err := CanonicalJSON(kg.Value)
+	if err != nil {
+		// Fallback to the Go representation, still deterministic
+		data = []byte(fmt.Sprintf("%#v", kg.Value))
+	}
+
+	sum := sha256.Sum256(data)
+	key := hex.EncodeToString(sum[:])
+	if kg.Length > 0 && kg.Length < len(key) {
+		key = key[:kg.Length]
+	}
+	return kg.Prefix + key
+}
+
+// CanonicalJSON encodes value with sorted object keys, no insignificant
+// whitespace and numbers as written, so equal objects encode identically
+// whether they are structs or maps.
+func CanonicalJSON(value interface{}) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling value: %v", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("error canonicalizing value: %v", err)
+	}
+	return json.Marshal(generic)
+}
+
 func NewTimestampKey(prefix string) string {
 	kg := &TimestampKeyGenerator{Prefix: prefix}
 	return kg.Generate()
@@ -96,3 +142,8 @@ func NewCompositeKey(parts ...string) string {
 	kg := &CompositeKeyGenerator{Parts: parts}
 	return kg.Generate()
 }
+
+func NewHashKey(prefix string, value interface{}) string {
+	kg := &HashKeyGenerator{Prefix: prefix, Value: value}
+	return kg.Generate()
+}
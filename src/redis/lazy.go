@@ -0,0 +1,75 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ReconnectInterval is the least time between two connection attempts of a
+// LazyStore while the store is down.
+const ReconnectInterval = 30 * time.Second
+
+// LazyStore hands out a store connected on demand, so plugins start while
+// Redis is down. While it stays down, connecting is tried again at most
+// every ReconnectInterval instead of on every request.
+type LazyStore struct {
+	name string
+	now  func() time.Time
+
+	mu          sync.Mutex
+	store       Store
+	connect     func() (Store, error)
+	nextConnect time.Time
+}
+
+// NewLazyStore returns store, nil when not connected yet, and opens it with
+// connect otherwise. name is used in errors, e.g. "API key" for "API key
+// store unavailable".
+func NewLazyStore(name string, store Store, connect func() (Store, error)) *LazyStore {
+	return &LazyStore{
+		name:    name,
+		now:     time.Now,
+		store:   store,
+		connect: connect,
+	}
+}
+
+// Get returns the store, connecting it first when needed.
+func (ls *LazyStore) Get() (Store, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if ls.store != nil {
+		return ls.store, nil
+	}
+	now := ls.now()
+	if now.Before(ls.nextConnect) || ls.connect == nil {
+		return nil, fmt.Errorf("%s store unavailable", ls.name)
+	}
+
+	store, err := ls.connect()
+	if err != nil {
+		ls.nextConnect = now.Add(ReconnectInterval)
+		return nil, fmt.Errorf("%s store unavailable: %v", ls.name, err)
+	}
+	if !ls.nextConnect.IsZero() {
+		fmt.Printf("Reconnected to the %s store\n", ls.name)
+	}
+	ls.store = store
+	return store, nil
+}
+
+// OpenUntilDone returns a connect function for NewLazyStore that opens the
+// store of config and closes it once ctx is done, see CloseOnDone.
+func OpenUntilDone(ctx context.Context, config *Config) func() (Store, error) {
+	return func() (Store, error) {
+		store, err := NewStore(config)
+		if err != nil {
+			return nil, err
+		}
+		CloseOnDone(ctx, config)
+		return store, nil
+	}
+}
@@ -0,0 +1,41 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLazyStore_ReconnectsAfterInterval(t *testing.T) {
+	attempts := 0
+	var down error = errors.New("connection refused")
+	store := NewMemoryStore(0)
+	ls := NewLazyStore("test", nil, func() (Store, error) {
+		attempts++
+		if down != nil {
+			return nil, down
+		}
+		return store, nil
+	})
+	now := time.Unix(1_700_000_000, 0)
+	ls.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := ls.Get(); err == nil {
+			t.Fatal("expected the store to be unavailable")
+		}
+	}
+	if attempts != 1 {
+		t.Fatalf("expected one attempt within the interval, got %d", attempts)
+	}
+
+	down = nil
+	now = now.Add(ReconnectInterval)
+	if got, err := ls.Get(); err != nil || got != store {
+		t.Fatalf("expected the store after the interval, got %v (%v)", got, err)
+	}
+	ls.Get()
+	if attempts != 2 {
+		t.Errorf("expected the connected store to be kept, got %d attempts", attempts)
+	}
+}
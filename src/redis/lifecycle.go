@@ -0,0 +1,83 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+type closeRegistration struct {
+	ctx context.Context
+	key string
+}
+
+var (
+	closeMu         sync.Mutex
+	closeRegistered = make(map[closeRegistration]bool)
+	closeRefs       = make(map[string]int)
+
+	closePool = func(key string) { GetClientManager().Close(key) }
+
+	shutdownOnce sync.Once
+)
+
+// Retain keeps the pool of config open until the process shuts down, for
+// holders without a context such as response modifiers. CloseOnDone leaves
+// a retained pool open when its contexts are done.
+func Retain(config *Config) {
+	closeMu.Lock()
+	defer closeMu.Unlock()
+	closeRefs[config.clientKey()]++
+}
+
+// CloseOnDone closes the pool of config once ctx, e.g. the context KrakenD
+// passes to plugins, is done and no other registered context or Retain
+// holds the pool. Registering the same context and pool again, e.g. on
+// every reconnect, is a no-op. Pools never registered stay open until the
+// process shuts down.
+func CloseOnDone(ctx context.Context, config *Config) {
+	registration := closeRegistration{ctx: ctx, key: config.clientKey()}
+
+	closeMu.Lock()
+	if closeRegistered[registration] {
+		closeMu.Unlock()
+		return
+	}
+	closeRegistered[registration] = true
+	closeRefs[registration.key]++
+	closeMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		closeMu.Lock()
+		delete(closeRegistered, registration)
+		closeRefs[registration.key]--
+		last := closeRefs[registration.key] == 0
+		if last {
+			delete(closeRefs, registration.key)
+		}
+		closeMu.Unlock()
+
+		if last {
+			closePool(registration.key)
+		}
+	}()
+}
+
+// closeOnShutdown closes every pool when the process receives SIGINT or
+// SIGTERM. The gateway handles the signal as well; this only releases the
+// connections. It is started with the first pool.
+func closeOnShutdown() {
+	shutdownOnce.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-signals
+			signal.Stop(signals)
+			GetClientManager().CloseAll()
+		}()
+	})
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCloseOnDone_ClosesPoolWithLastContext(t *testing.T) {
+	var mu sync.Mutex
+	closed := make([]string, 0)
+	closePool = func(key string) {
+		mu.Lock()
+		defer mu.Unlock()
+		closed = append(closed, key)
+	}
+	defer func() { closePool = func(key string) { GetClientManager().Close(key) } }()
+
+	closedKeys := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), closed...)
+	}
+
+	shared := &Config{RedisMode: ModeStandalone, RedisAddr: "redis:6379"}
+	other := &Config{RedisMode: ModeStandalone, RedisAddr: "redis:6379", RedisDB: 1}
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	second, cancelSecond := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		// Reconnects register the same context again.
+		CloseOnDone(first, shared)
+	}
+	CloseOnDone(second, shared)
+	CloseOnDone(second, other)
+
+	closeMu.Lock()
+	refs := closeRefs[shared.clientKey()]
+	closeMu.Unlock()
+	if refs != 2 {
+		t.Fatalf("expected one registration per context, got %d", refs)
+	}
+
+	cancelFirst()
+	time.Sleep(20 * time.Millisecond)
+	if keys := closedKeys(); len(keys) != 0 {
+		t.Fatalf("expected pools still used by the second context to stay open, closed %v", keys)
+	}
+
+	cancelSecond()
+	deadline := time.Now().Add(time.Second)
+	for len(closedKeys()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	keys := closedKeys()
+	if len(keys) != 2 {
+		t.Fatalf("expected both pools closed once, got %v", keys)
+	}
+	for _, key := range keys {
+		if key != shared.clientKey() && key != other.clientKey() {
+			t.Errorf("unexpected pool closed: %s", key)
+		}
+	}
+}
+
+func TestCloseOnDone_KeepsRetainedPoolsOpen(t *testing.T) {
+	closed := make(chan string, 1)
+	closePool = func(key string) { closed <- key }
+	defer func() { closePool = func(key string) { GetClientManager().Close(key) } }()
+
+	config := &Config{RedisMode: ModeStandalone, RedisAddr: "redis:6379", RedisDB: 2}
+	Retain(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	CloseOnDone(ctx, config)
+	cancel()
+
+	select {
+	case key := <-closed:
+		t.Errorf("expected the retained pool to stay open, closed %s", key)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
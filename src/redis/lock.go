@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Lock is a lease on a key shared by every gateway replica. The key holds a
+// random token so only the owner can renew or release it, and the lease is
+// renewed in the background until Release is called.
+type Lock struct {
+	store Store
+	key   string
+	token string
+	ttl   int
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// AcquireLock tries once to take the lock. It returns false without error
+// when another owner holds it.
+func AcquireLock(ctx context.Context, store Store, key string, ttl int) (*Lock, bool, error) {
+	if ttl <= 0 {
+		return nil, false, fmt.Errorf("lock ttl must be greater than 0")
+	}
+
+	token := NewUUIDKey("")
+	acquired, err := store.SetNX(ctx, key, token, ttl)
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+
+	lock := &Lock{
+		store: store,
+		key:   key,
+		token: token,
+		ttl:   ttl,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go lock.renew()
+	return lock, true, nil
+}
+
+// renew extends the lease every third of its ttl and gives up when the
+// lock is lost, e.g. after a long pause let it expire.
+func (l *Lock) renew() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(time.Duration(l.ttl) * time.Second / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(l.ttl)*time.Second/3)
+			renewed, err := l.store.CompareAndExpire(ctx, l.key, l.token, l.ttl)
+			cancel()
+			if err != nil {
+				log.Printf("[REDIS-LOCK] Error renewing %s: %v", l.key, err)
+				continue
+			}
+			if !renewed {
+				log.Printf("[REDIS-LOCK] Lost lock %s", l.key)
+				return
+			}
+		}
+	}
+}
+
+// Release stops the renewal and deletes the key if this owner still holds
+// it. It is safe to call more than once.
+func (l *Lock) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+
+	if _, err := l.store.CompareAndDelete(ctx, l.key, l.token); err != nil {
+		return fmt.Errorf("error releasing lock %s: %v", l.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLock_ExclusiveAndSafeRelease(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+
+	first, acquired, err := AcquireLock(ctx, store, "lock:search", 30)
+	if err != nil || !acquired {
+		t.Fatalf("expected first lock to be acquired, got %v (%v)", acquired, err)
+	}
+	if _, acquired, _ := AcquireLock(ctx, store, "lock:search", 30); acquired {
+		t.Fatal("expected second lock to fail while the first is held")
+	}
+
+	// Simulate the lease expiring and another owner taking over.
+	store.Delete(ctx, "lock:search")
+	second, acquired, _ := AcquireLock(ctx, store, "lock:search", 30)
+	if !acquired {
+		t.Fatal("expected lock to be acquired after expiry")
+	}
+
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := store.Get(ctx, "lock:search"); err != nil {
+		t.Error("expected stale owner release to keep the new owner's lock")
+	}
+
+	second.Release(ctx)
+	if _, err := store.Get(ctx, "lock:search"); !IsNil(err) {
+		t.Errorf("expected lock to be released, got %v", err)
+	}
+}
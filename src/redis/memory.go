@@ -0,0 +1,322 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	memoryStoresMu sync.Mutex
+	memoryStores   = make(map[string]*MemoryStore)
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryStore is an in-process Store with Redis-like TTL semantics. Expired
+// keys are dropped lazily when they are read or scanned.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	ttl     int
+	now     func() time.Time
+}
+
+func NewMemoryStore(defaultTTL int) *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		ttl:     defaultTTL,
+		now:     time.Now,
+	}
+}
+
+// GetMemoryStore returns the store shared by every caller using the same
+// key prefix, the in-process equivalent of pointing at the same Redis.
+func GetMemoryStore(config *Config) *MemoryStore {
+	memoryStoresMu.Lock()
+	defer memoryStoresMu.Unlock()
+
+	store, exists := memoryStores[config.KeyPrefix]
+	if !exists {
+		store = NewMemoryStore(config.KeyTTL)
+		memoryStores[config.KeyPrefix] = store
+	}
+	return store
+}
+
+func (ms *MemoryStore) Set(ctx context.Context, key string, value interface{}) error {
+	return ms.SetWithTTL(ctx, key, value, ms.ttl)
+}
+
+func (ms *MemoryStore) SetWithTTL(ctx context.Context, key string, value interface{}, ttl int) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.entries[key] = memoryEntry{value: data, expiresAt: ms.expiresAt(ttl)}
+	return nil
+}
+
+// Get returns redis.Nil for missing keys so IsNil works for both stores.
+func (ms *MemoryStore) Get(ctx context.Context, key string) (string, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, exists := ms.lookup(key)
+	if !exists {
+		return "", redis.Nil
+	}
+	return string(entry.value), nil
+}
+
+func (ms *MemoryStore) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	data, err := ms.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), dest)
+}
+
+func (ms *MemoryStore) SetNX(ctx context.Context, key string, value interface{}, ttl int) (bool, error) {
+	data, err := encodeValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if _, exists := ms.lookup(key); exists {
+		return false, nil
+	}
+	ms.entries[key] = memoryEntry{value: data, expiresAt: ms.expiresAt(ttl)}
+	return true, nil
+}
+
+func (ms *MemoryStore) MSet(ctx context.Context, items []Item) BatchErrors {
+	errs := make(BatchErrors)
+	for _, item := range items {
+		ttl := item.TTL
+		if ttl == 0 {
+			ttl = ms.ttl
+		}
+		if err := ms.SetWithTTL(ctx, item.Key, item.Value, ttl); err != nil {
+			errs[item.Key] = err
+		}
+	}
+	return errs
+}
+
+func (ms *MemoryStore) MGet(ctx context.Context, keys []string) (map[string]string, BatchErrors) {
+	values := make(map[string]string)
+	for _, key := range keys {
+		if value, err := ms.Get(ctx, key); err == nil {
+			values[key] = value
+		}
+	}
+	return values, make(BatchErrors)
+}
+
+// Expire with a ttl of 0 or less deletes the key, as Redis does.
+func (ms *MemoryStore) Expire(ctx context.Context, key string, ttl int) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, exists := ms.lookup(key)
+	if !exists {
+		return nil
+	}
+	if ttl <= 0 {
+		delete(ms.entries, key)
+		return nil
+	}
+	entry.expiresAt = ms.expiresAt(ttl)
+	ms.entries[key] = entry
+	return nil
+}
+
+func (ms *MemoryStore) Increment(ctx context.Context, key string) (int64, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, _ := ms.lookup(key)
+	var current int64
+	if entry.value != nil {
+		n, err := strconv.ParseInt(string(entry.value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value of %s is not an integer", key)
+		}
+		current = n
+	}
+	current++
+	entry.value = []byte(strconv.FormatInt(current, 10))
+	ms.entries[key] = entry
+	return current, nil
+}
+
+func (ms *MemoryStore) Delete(ctx context.Context, key string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.entries, key)
+	return nil
+}
+
+func (ms *MemoryStore) CompareAndDelete(ctx context.Context, key string, value string) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, exists := ms.lookup(key)
+	if !exists || string(entry.value) != value {
+		return false, nil
+	}
+	delete(ms.entries, key)
+	return true, nil
+}
+
+func (ms *MemoryStore) CompareAndExpire(ctx context.Context, key string, value string, ttl int) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, exists := ms.lookup(key)
+	if !exists || string(entry.value) != value {
+		return false, nil
+	}
+	entry.expiresAt = ms.expiresAt(ttl)
+	ms.entries[key] = entry
+	return true, nil
+}
+
+// Scan takes a snapshot of the matching keys; count is ignored.
+func (ms *MemoryStore) Scan(ctx context.Context, pattern string, count int64) Iterator {
+	matcher, err := globToRegexp(pattern)
+	if err != nil {
+		return &sliceIterator{err: err}
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	keys := make([]string, 0)
+	for key := range ms.entries {
+		if _, exists := ms.lookup(key); exists && matcher.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return &sliceIterator{keys: keys, pos: -1}
+}
+
+func (ms *MemoryStore) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	it := ms.Scan(ctx, pattern, 0)
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var deleted int64
+	for it.Next(ctx) {
+		if _, exists := ms.entries[it.Key()]; exists {
+			delete(ms.entries, it.Key())
+			deleted++
+		}
+	}
+	return deleted, it.Err()
+}
+
+func (ms *MemoryStore) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// lookup returns a live entry, dropping it if it has expired. Callers hold mu.
+func (ms *MemoryStore) lookup(key string) (memoryEntry, bool) {
+	entry, exists := ms.entries[key]
+	if !exists {
+		return memoryEntry{}, false
+	}
+	if entry.expired(ms.now()) {
+		delete(ms.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+func (ms *MemoryStore) expiresAt(ttl int) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return ms.now().Add(time.Duration(ttl) * time.Second)
+}
+
+type sliceIterator struct {
+	keys []string
+	pos  int
+	err  error
+}
+
+func (it *sliceIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	it.pos++
+	return it.pos < len(it.keys)
+}
+
+func (it *sliceIterator) Key() string {
+	return it.keys[it.pos]
+}
+
+func (it *sliceIterator) Err() error {
+	return it.err
+}
+
+// globToRegexp translates a Redis MATCH pattern (*, ?, [...] and \ escapes)
+// into an anchored regular expression.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString("(?s:.*)")
+		case '?':
+			b.WriteString("(?s:.)")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			b.WriteString("[" + pattern[i+1:i+1+end] + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_TTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore(60)
+	store.now = func() time.Time { return now }
+
+	if err := store.SetWithTTL(ctx, "a", "1", 10); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if value, err := store.Get(ctx, "a"); err != nil || value != "1" {
+		t.Fatalf("expected 1, got %q (%v)", value, err)
+	}
+
+	now = now.Add(11 * time.Second)
+	if _, err := store.Get(ctx, "a"); !IsNil(err) {
+		t.Errorf("expected expired key to be nil, got %v", err)
+	}
+	if ok, _ := store.SetNX(ctx, "a", "2", 10); !ok {
+		t.Error("expected SetNX to succeed on an expired key")
+	}
+}
+
+func TestMemoryStore_Increment(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := store.Increment(ctx, "counter")
+		if err != nil || got != want {
+			t.Fatalf("expected %d, got %d (%v)", want, got, err)
+		}
+	}
+
+	store.Set(ctx, "text", "abc")
+	if _, err := store.Increment(ctx, "text"); err == nil {
+		t.Error("expected error incrementing a non integer value")
+	}
+}
+
+func TestMemoryStore_ScanAndDeleteByPattern(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+	for _, key := range []string{"search:TBO:MIA:1", "search:TBO:NYC:2", "search:Expedia:MIA:3", "search:TBO:c*ty:4"} {
+		store.Set(ctx, key, "x")
+	}
+
+	deleted, err := store.DeleteByPattern(ctx, "search:TBO:*")
+	if err != nil || deleted != 3 {
+		t.Fatalf("expected 3 deleted keys, got %d (%v)", deleted, err)
+	}
+
+	var keys []string
+	it := store.Scan(ctx, "search:*:MIA:?", 0)
+	for it.Next(ctx) {
+		keys = append(keys, it.Key())
+	}
+	if len(keys) != 1 || keys[0] != "search:Expedia:MIA:3" {
+		t.Errorf("expected only the Expedia key, got %v", keys)
+	}
+
+	store.Set(ctx, "search:TBO:c*ty:4", "x")
+	store.Set(ctx, "search:TBO:city:5", "x")
+	if deleted, _ := store.DeleteByPattern(ctx, "search:TBO:"+EscapePattern("c*ty")+":*"); deleted != 1 {
+		t.Errorf("expected escaped pattern to delete 1 key, got %d", deleted)
+	}
+}
+
+func TestMemoryStore_MSetAndMGet(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(60)
+
+	errs := store.MSet(ctx, []Item{
+		{Key: "a", Value: "1"},
+		{Key: "b", Value: map[string]int{"n": 2}, TTL: 10},
+		{Key: "c", Value: func() {}},
+	})
+	if _, failed := errs["c"]; !failed || len(errs) != 1 || errs.Err() == nil {
+		t.Fatalf("expected only c to fail, got %v", errs)
+	}
+
+	values, errs := store.MGet(ctx, []string{"a", "b", "missing"})
+	if errs.Err() != nil {
+		t.Fatalf("expected no errors, got %v", errs.Err())
+	}
+	if len(values) != 2 || values["a"] != "1" || values["b"] != `{"n":2}` {
+		t.Errorf("unexpected values %v", values)
+	}
+}
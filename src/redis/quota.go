@@ -0,0 +1,162 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Both scripts read the clock of the Redis server so replicas with skewed
+// clocks share one view of the bucket and the leases.
+var (
+	takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local data = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(data[1]) or burst
+local ts = tonumber(data[2]) or now
+tokens = math.min(burst, tokens + math.max(now - ts, 0) * rate)
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate) + 1000)
+return wait`)
+
+	acquireSlotScript = redis.NewScript(`
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if redis.call("ZSCORE", KEYS[1], ARGV[1]) or redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("ZADD", KEYS[1], now + tonumber(ARGV[3]), ARGV[1])
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+	return 1
+end
+return 0`)
+)
+
+// TakeToken removes one token from the bucket at key, refilled at rate
+// tokens per second up to burst. When the bucket is empty nothing is taken
+// and it returns how long until the next token.
+func (rc *RedisClient) TakeToken(ctx context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	fullKey := rc.config.KeyPrefix + key
+	wait, err := takeTokenScript.Run(ctx, rc.client, []string{fullKey}, rate/1000, burst).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// AcquireSlot registers holder at key unless limit holders are already
+// there. Slots are leases: a holder that never releases, e.g. a crashed
+// replica, is dropped after ttl.
+func (rc *RedisClient) AcquireSlot(ctx context.Context, key string, holder string, limit int, ttl time.Duration) (bool, error) {
+	fullKey := rc.config.KeyPrefix + key
+	n, err := acquireSlotScript.Run(ctx, rc.client, []string{fullKey}, holder, limit, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+func (rc *RedisClient) ReleaseSlot(ctx context.Context, key string, holder string) error {
+	fullKey := rc.config.KeyPrefix + key
+	return rc.client.ZRem(ctx, fullKey, holder).Err()
+}
+
+type memoryBucket struct {
+	Tokens float64   `json:"tokens"`
+	At     time.Time `json:"at"`
+}
+
+func (ms *MemoryStore) TakeToken(ctx context.Context, key string, rate float64, burst int) (time.Duration, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.now()
+	bucket := memoryBucket{Tokens: float64(burst), At: now}
+	if entry, exists := ms.lookup(key); exists {
+		if err := json.Unmarshal(entry.value, &bucket); err != nil {
+			return 0, err
+		}
+	}
+
+	elapsed := max(now.Sub(bucket.At).Seconds(), 0)
+	bucket.Tokens = math.Min(float64(burst), bucket.Tokens+elapsed*rate)
+	bucket.At = now
+
+	var wait time.Duration
+	if bucket.Tokens >= 1 {
+		bucket.Tokens--
+	} else {
+		wait = time.Duration(math.Ceil((1-bucket.Tokens)/rate*1000)) * time.Millisecond
+	}
+
+	data, _ := json.Marshal(bucket)
+	ms.entries[key] = memoryEntry{
+		value:     data,
+		expiresAt: now.Add(time.Duration(float64(burst)/rate*float64(time.Second)) + time.Second),
+	}
+	return wait, nil
+}
+
+func (ms *MemoryStore) AcquireSlot(ctx context.Context, key string, holder string, limit int, ttl time.Duration) (bool, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.now()
+	slots, err := ms.slots(key, now)
+	if err != nil {
+		return false, err
+	}
+	if _, held := slots[holder]; !held && len(slots) >= limit {
+		return false, nil
+	}
+
+	slots[holder] = now.Add(ttl)
+	data, _ := json.Marshal(slots)
+	ms.entries[key] = memoryEntry{value: data, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (ms *MemoryStore) ReleaseSlot(ctx context.Context, key string, holder string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, exists := ms.lookup(key)
+	if !exists {
+		return nil
+	}
+	slots, err := ms.slots(key, ms.now())
+	if err != nil {
+		return err
+	}
+	delete(slots, holder)
+	data, _ := json.Marshal(slots)
+	entry.value = data
+	ms.entries[key] = entry
+	return nil
+}
+
+// slots returns the unexpired holders at key. Callers hold mu.
+func (ms *MemoryStore) slots(key string, now time.Time) (map[string]time.Time, error) {
+	slots := make(map[string]time.Time)
+	entry, exists := ms.lookup(key)
+	if !exists {
+		return slots, nil
+	}
+	if err := json.Unmarshal(entry.value, &slots); err != nil {
+		return nil, err
+	}
+	for holder, expiresAt := range slots {
+		if !now.Before(expiresAt) {
+			delete(slots, holder)
+		}
+	}
+	return slots, nil
+}
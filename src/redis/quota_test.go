@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_TakeToken(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if wait, err := store.TakeToken(ctx, "bucket", 2, 2); err != nil || wait != 0 {
+			t.Fatalf("token %d: expected no wait, got %s (%v)", i, wait, err)
+		}
+	}
+	wait, _ := store.TakeToken(ctx, "bucket", 2, 2)
+	if wait != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms for the next token, got %s", wait)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if wait, _ := store.TakeToken(ctx, "bucket", 2, 2); wait != 0 {
+		t.Errorf("expected a refilled token, got wait %s", wait)
+	}
+}
+
+func TestMemoryStore_Slots(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+
+	for _, holder := range []string{"a", "b"} {
+		if ok, _ := store.AcquireSlot(ctx, "slots", holder, 2, time.Minute); !ok {
+			t.Fatalf("expected %s to get a slot", holder)
+		}
+	}
+	if ok, _ := store.AcquireSlot(ctx, "slots", "c", 2, time.Minute); ok {
+		t.Fatal("expected the third holder to be refused")
+	}
+
+	store.ReleaseSlot(ctx, "slots", "a")
+	if ok, _ := store.AcquireSlot(ctx, "slots", "c", 2, time.Minute); !ok {
+		t.Fatal("expected a released slot to be reused")
+	}
+
+	// Leases of holders that never release expire.
+	now = now.Add(2 * time.Minute)
+	if ok, _ := store.AcquireSlot(ctx, "slots", "d", 1, time.Minute); !ok {
+		t.Error("expected expired slots to be freed")
+	}
+}
@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RateLimit allows Limit requests per Window.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long a rejected caller should wait. Reset is when
+	// the current window ends.
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+// SlidingWindow counts a request against key with the sliding window
+// counter algorithm: the previous fixed window is weighted by how much of it
+// still overlaps the sliding window. Counters are shared through the store,
+// so the limit holds across replicas. Rejected requests count too, so a
+// caller ignoring Retry-After stays limited.
+func SlidingWindow(ctx context.Context, store Store, key string, limit RateLimit, now time.Time) (RateLimitResult, error) {
+	result := RateLimitResult{Limit: limit.Limit}
+	if limit.Limit <= 0 || limit.Window <= 0 {
+		return result, fmt.Errorf("invalid rate limit %d per %s", limit.Limit, limit.Window)
+	}
+
+	window := limit.Window
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() - index*int64(window))
+	currentKey := key + ":" + strconv.FormatInt(index, 10)
+	previousKey := key + ":" + strconv.FormatInt(index-1, 10)
+
+	current, err := store.Increment(ctx, currentKey)
+	if err != nil {
+		return result, fmt.Errorf("error counting request: %v", err)
+	}
+	if current == 1 {
+		// The counter is read as the previous window during the next one.
+		ttl := int(math.Ceil((2 * window).Seconds()))
+		if err := store.Expire(ctx, currentKey, ttl); err != nil {
+			return result, fmt.Errorf("error setting rate limit expiry: %v", err)
+		}
+	}
+
+	var previous int64
+	if value, err := store.Get(ctx, previousKey); err == nil {
+		previous, _ = strconv.ParseInt(value, 10, 64)
+	} else if !IsNil(err) {
+		return result, fmt.Errorf("error reading previous window: %v", err)
+	}
+
+	weight := 1 - float64(elapsed)/float64(window)
+	estimate := float64(previous)*weight + float64(current)
+
+	result.Reset = window - elapsed
+	result.Allowed = estimate <= float64(limit.Limit)
+	result.Remaining = max(limit.Limit-int(math.Ceil(estimate)), 0)
+	if !result.Allowed {
+		result.RetryAfter = retryAfter(float64(previous), float64(current), float64(limit.Limit), elapsed, window)
+	}
+	return result, nil
+}
+
+// retryAfter estimates when one more request fits under the limit, assuming
+// no further requests arrive meanwhile.
+func retryAfter(previous, current, limit float64, elapsed, window time.Duration) time.Duration {
+	target := limit - 1
+	if current <= target && previous > 0 {
+		// Still within this window: previous*(1-f) + current <= target.
+		f := 1 - (target-current)/previous
+		return max(time.Duration(f*float64(window))-elapsed, 0)
+	}
+	// After the window ends the current count becomes the previous one.
+	f := 1 - target/current
+	return window - elapsed + time.Duration(f*float64(window))
+}
@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSlidingWindow_LimitsAcrossWindows(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+	limit := RateLimit{Limit: 3, Window: time.Minute}
+	start := time.Unix(1_700_000_040, 0)
+
+	for i := 0; i < 3; i++ {
+		result, err := SlidingWindow(ctx, store, "rl:acme", limit, start)
+		if err != nil || !result.Allowed {
+			t.Fatalf("request %d: expected allowed, got %+v (%v)", i, result, err)
+		}
+		if result.Remaining != 2-i {
+			t.Errorf("request %d: expected %d remaining, got %d", i, 2-i, result.Remaining)
+		}
+	}
+
+	result, _ := SlidingWindow(ctx, store, "rl:acme", limit, start)
+	if result.Allowed || result.RetryAfter <= 0 {
+		t.Fatalf("expected fourth request to be limited with a retry delay, got %+v", result)
+	}
+
+	// Early in the next window the previous one still weighs almost fully.
+	if result, _ := SlidingWindow(ctx, store, "rl:acme", limit, start.Add(time.Minute)); result.Allowed {
+		t.Errorf("expected previous window to still count, got %+v", result)
+	}
+
+	// Two windows later nothing is left.
+	if result, _ := SlidingWindow(ctx, store, "rl:acme", limit, start.Add(3*time.Minute)); !result.Allowed {
+		t.Errorf("expected request to be allowed after the windows passed, got %+v", result)
+	}
+}
+
+func TestSlidingWindow_InvalidLimit(t *testing.T) {
+	if _, err := SlidingWindow(context.Background(), NewMemoryStore(0), "rl", RateLimit{}, time.Now()); err == nil {
+		t.Error("expected an error for an empty limit")
+	}
+}
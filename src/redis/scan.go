@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultScanCount = 100
+
+// KeyIterator walks the keys matching a pattern with SCAN, node by node in
+// cluster mode. Keys are returned without the configured prefix.
+type KeyIterator struct {
+	prefix  string
+	pattern string
+	count   int64
+	nodes   []redis.UniversalClient
+	current *redis.ScanIterator
+	key     string
+	err     error
+}
+
+// Scan returns an iterator over the keys matching pattern. count is a hint
+// for the number of keys fetched per round trip; 0 uses a default of 100.
+func (rc *RedisClient) Scan(ctx context.Context, pattern string, count int64) Iterator {
+	if count <= 0 {
+		count = defaultScanCount
+	}
+
+	it := &KeyIterator{
+		prefix:  rc.config.KeyPrefix,
+		pattern: rc.config.KeyPrefix + pattern,
+		count:   count,
+	}
+
+	cluster, ok := rc.client.(*redis.ClusterClient)
+	if !ok {
+		it.nodes = []redis.UniversalClient{rc.client}
+		return it
+	}
+
+	var mu sync.Mutex
+	it.err = cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+		it.nodes = append(it.nodes, node)
+		return nil
+	})
+	return it
+}
+
+// Next advances to the next key. It returns false when the keys are
+// exhausted or an error occurred; check Err afterwards.
+func (it *KeyIterator) Next(ctx context.Context) bool {
+	for it.err == nil {
+		if it.current == nil {
+			if len(it.nodes) == 0 {
+				return false
+			}
+			it.current = it.nodes[0].Scan(ctx, 0, it.pattern, it.count).Iterator()
+			it.nodes = it.nodes[1:]
+		}
+
+		if it.current.Next(ctx) {
+			it.key = strings.TrimPrefix(it.current.Val(), it.prefix)
+			return true
+		}
+		it.err = it.current.Err()
+		it.current = nil
+	}
+	return false
+}
+
+func (it *KeyIterator) Key() string {
+	return it.key
+}
+
+func (it *KeyIterator) Err() error {
+	return it.err
+}
+
+// DeleteByPattern removes every key matching pattern in batches and returns
+// how many were deleted. UNLINK frees the memory outside the main thread.
+func (rc *RedisClient) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	var deleted int64
+	batch := make([]string, 0, defaultScanCount)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		cmds, err := rc.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range batch {
+				pipe.Unlink(ctx, rc.config.KeyPrefix+key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			deleted += cmd.(*redis.IntCmd).Val()
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	it := rc.Scan(ctx, pattern, defaultScanCount)
+	for it.Next(ctx) {
+		batch = append(batch, it.Key())
+		if len(batch) == cap(batch) {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return deleted, err
+	}
+	return deleted, flush()
+}
+
+// EscapePattern escapes the glob characters of a literal key segment.
+func EscapePattern(segment string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+	return replacer.Replace(segment)
+}
@@ -0,0 +1,139 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	NamespaceSearch     = "search"
+	NamespacePriceCheck = "pricecheck"
+	NamespaceBooking    = "booking"
+	NamespaceAPIKey     = "apikey"
+	NamespaceRateLimit  = "ratelimit"
+	NamespaceQuota      = "quota"
+	NamespaceLookToBook = "looktobook"
+)
+
+// Namespace is a family of keys sharing a format. The version is part of
+// every key, so bumping it when the stored format changes makes readers
+// ignore old values, which then expire with their TTL.
+type Namespace struct {
+	Name    string
+	Version int
+	TTL     int
+}
+
+// Key joins parts under the versioned namespace, e.g. "search:v1:TBO:MIA".
+func (n Namespace) Key(parts ...string) string {
+	return n.prefix() + strings.Join(parts, ":")
+}
+
+// HashKey is Key with the content hash of value as the last part.
+func (n Namespace) HashKey(value interface{}, parts ...string) string {
+	return n.Key(append(parts, NewHashKey("", value))...)
+}
+
+// Pattern matches every key of the current version below parts.
+func (n Namespace) Pattern(parts ...string) string {
+	escaped := make([]string, 0, len(parts)+1)
+	for _, part := range parts {
+		escaped = append(escaped, EscapePattern(part))
+	}
+	return n.prefix() + strings.Join(append(escaped, "*"), ":")
+}
+
+func (n Namespace) prefix() string {
+	return fmt.Sprintf("%s:v%d:", n.Name, n.Version)
+}
+
+// DeleteOldVersions removes the keys written by previous versions of the
+// namespace instead of waiting for them to expire.
+func (n Namespace) DeleteOldVersions(ctx context.Context, store Store) (int64, error) {
+	var deleted int64
+	for version := 1; version < n.Version; version++ {
+		old := Namespace{Name: n.Name, Version: version}
+		count, err := store.DeleteByPattern(ctx, old.Pattern())
+		deleted += count
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// SchemaRegistry declares the namespaces in use.
+type SchemaRegistry struct {
+	mu         sync.RWMutex
+	namespaces map[string]Namespace
+}
+
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		namespaces: make(map[string]Namespace),
+	}
+}
+
+func (sr *SchemaRegistry) Register(namespace Namespace) error {
+	if namespace.Name == "" || strings.Contains(namespace.Name, ":") {
+		return fmt.Errorf("invalid namespace name %q", namespace.Name)
+	}
+	if namespace.Version < 1 {
+		return fmt.Errorf("namespace %s: version must be at least 1", namespace.Name)
+	}
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if _, exists := sr.namespaces[namespace.Name]; exists {
+		return fmt.Errorf("namespace %s is already registered", namespace.Name)
+	}
+	sr.namespaces[namespace.Name] = namespace
+	return nil
+}
+
+func (sr *SchemaRegistry) Get(name string) (Namespace, bool) {
+	sr.mu.RLock()
+	defer sr.mu.RUnlock()
+	namespace, exists := sr.namespaces[name]
+	return namespace, exists
+}
+
+// MustGet is Get for namespaces declared in code; a missing one is a bug.
+func (sr *SchemaRegistry) MustGet(name string) Namespace {
+	namespace, exists := sr.Get(name)
+	if !exists {
+		panic(fmt.Sprintf("redis: namespace %s is not registered", name))
+	}
+	return namespace
+}
+
+var defaultSchema = NewSchemaRegistry()
+
+func init() {
+	for _, namespace := range []Namespace{
+		{Name: NamespaceSearch, Version: 1, TTL: 300},
+		{Name: NamespacePriceCheck, Version: 1, TTL: 1800},
+		{Name: NamespaceBooking, Version: 1, TTL: 86400},
+		// API keys are provisioned by the back office and never expire.
+		{Name: NamespaceAPIKey, Version: 1},
+		{Name: NamespaceRateLimit, Version: 1},
+		{Name: NamespaceQuota, Version: 1},
+		{Name: NamespaceLookToBook, Version: 1, TTL: 35 * 86400},
+	} {
+		if err := defaultSchema.Register(namespace); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// DefaultSchema returns the registry with the gateway's built-in
+// namespaces.
+func DefaultSchema() *SchemaRegistry {
+	return defaultSchema
+}
+
+func GetNamespace(name string) Namespace {
+	return defaultSchema.MustGet(name)
+}
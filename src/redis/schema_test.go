@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHashKeyGenerator_CanonicalContent(t *testing.T) {
+	type search struct {
+		City   string `json:"city"`
+		Adults int    `json:"adults"`
+	}
+
+	a := NewHashKey("h:", search{City: "MIA", Adults: 2})
+	b := NewHashKey("h:", map[string]interface{}{"adults": 2, "city": "MIA"})
+	if a != b {
+		t.Errorf("expected struct and map with the same content to match, got %s and %s", a, b)
+	}
+	if c := NewHashKey("h:", search{City: "MIA", Adults: 3}); c == a {
+		t.Error("expected different content to produce a different key")
+	}
+	if short := (&HashKeyGenerator{Value: "x", Length: 12}).Generate(); len(short) != 12 {
+		t.Errorf("expected a 12 character key, got %s", short)
+	}
+}
+
+func TestNamespace_VersionedKeys(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(0)
+
+	v1 := Namespace{Name: "search", Version: 1}
+	v2 := Namespace{Name: "search", Version: 2}
+	store.Set(ctx, v1.Key("TBO", "MIA", "abc"), "old")
+	store.Set(ctx, v2.Key("TBO", "MIA", "abc"), "new")
+
+	if _, err := store.Get(ctx, v2.Key("TBO", "MIA", "abc")); err != nil {
+		t.Fatalf("expected current version to be readable, got %v", err)
+	}
+	if deleted, err := v2.DeleteOldVersions(ctx, store); err != nil || deleted != 1 {
+		t.Errorf("expected 1 old key deleted, got %d (%v)", deleted, err)
+	}
+	if _, err := store.Get(ctx, v2.Key("TBO", "MIA", "abc")); err != nil {
+		t.Errorf("expected current version to survive, got %v", err)
+	}
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	registry := NewSchemaRegistry()
+	if err := registry.Register(Namespace{Name: "booking", Version: 1, TTL: 60}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := registry.Register(Namespace{Name: "booking", Version: 2}); err == nil {
+		t.Error("expected duplicate namespace to be rejected")
+	}
+	if err := registry.Register(Namespace{Name: "bad:name", Version: 1}); err == nil {
+		t.Error("expected invalid name to be rejected")
+	}
+	if ns := GetNamespace(NamespacePriceCheck); ns.Key("expedia", "1") != "pricecheck:v1:expedia:1" {
+		t.Errorf("unexpected price check key %s", ns.Key("expedia", "1"))
+	}
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSConfig enables TLS towards Redis. Files are paths inside the gateway
+// container, usually a mounted secret.
+type TLSConfig struct {
+	Enabled            bool   `json:"enabled"`
+	CAFile             string `json:"ca_file"`
+	CertFile           string `json:"cert_file"`
+	KeyFile            string `json:"key_file"`
+	ServerName         string `json:"server_name"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+func (t *TLSConfig) Validate() error {
+	if t == nil || !t.Enabled {
+		return nil
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("redis_tls cert_file and key_file must be set together")
+	}
+	return nil
+}
+
+// Build returns nil when TLS is disabled so the go-redis options keep a
+// plain TCP connection.
+func (t *TLSConfig) Build() (*tls.Config, error) {
+	if t == nil || !t.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		ca, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading redis CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in redis CA file %s", t.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading redis client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// ResolveSecret returns the first value found in the secret file, the
+// environment variable or the inline value, in that order.
+func ResolveSecret(inline, envName, filePath string) (string, error) {
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", fmt.Errorf("error reading secret file %s: %v", filePath, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	if envName != "" {
+		if value, ok := os.LookupEnv(envName); ok {
+			return value, nil
+		}
+		return "", fmt.Errorf("environment variable %s is not set", envName)
+	}
+	return inline, nil
+}
@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	StoreRedis  = "redis"
+	StoreMemory = "memory"
+)
+
+// Store is the key-value API the plugins depend on. RedisClient is the
+// production implementation; MemoryStore runs in-process for tests and
+// local runs without Redis. Keys are given without the configured prefix.
+type Store interface {
+	Set(ctx context.Context, key string, value interface{}) error
+	SetWithTTL(ctx context.Context, key string, value interface{}, ttl int) error
+	Get(ctx context.Context, key string) (string, error)
+	GetJSON(ctx context.Context, key string, dest interface{}) error
+	SetNX(ctx context.Context, key string, value interface{}, ttl int) (bool, error)
+	Expire(ctx context.Context, key string, ttl int) error
+	Increment(ctx context.Context, key string) (int64, error)
+	Delete(ctx context.Context, key string) error
+	MSet(ctx context.Context, items []Item) BatchErrors
+	MGet(ctx context.Context, keys []string) (map[string]string, BatchErrors)
+	// CompareAndDelete and CompareAndExpire only act when key still holds
+	// value, atomically. Locks use them to release and renew safely.
+	CompareAndDelete(ctx context.Context, key string, value string) (bool, error)
+	CompareAndExpire(ctx context.Context, key string, value string, ttl int) (bool, error)
+	// TakeToken, AcquireSlot and ReleaseSlot share call quotas between
+	// replicas: a token bucket and a count of calls in flight.
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (time.Duration, error)
+	AcquireSlot(ctx context.Context, key string, holder string, limit int, ttl time.Duration) (bool, error)
+	ReleaseSlot(ctx context.Context, key string, holder string) error
+	Scan(ctx context.Context, pattern string, count int64) Iterator
+	DeleteByPattern(ctx context.Context, pattern string) (int64, error)
+	Ping(ctx context.Context) error
+}
+
+// Iterator streams the keys returned by Store.Scan.
+type Iterator interface {
+	Next(ctx context.Context) bool
+	Key() string
+	Err() error
+}
+
+// NewStore builds the store selected by the "store" setting.
+func NewStore(config *Config) (Store, error) {
+	switch config.Store {
+	case "", StoreRedis:
+		return NewRedisClient(config)
+	case StoreMemory:
+		return GetMemoryStore(config), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q, expected %s or %s", config.Store, StoreRedis, StoreMemory)
+	}
+}
+
+// HealthChecker is implemented by stores with a background health check.
+type HealthChecker interface {
+	Healthy() bool
+}
+
+// IsHealthy reports whether store can be used. Stores without a health
+// check, such as MemoryStore, are always healthy.
+func IsHealthy(store Store) bool {
+	if store == nil {
+		return false
+	}
+	if checker, ok := store.(HealthChecker); ok {
+		return checker.Healthy()
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"cars-common/core"
+	"cars-common/models"
+	"cars-common/transformers"
+	"fmt"
+)
+
+const PluginName = "rently-booking"
+
+var pluginCore *core.CarPluginCore
+
+var requestModifiers = map[string]models.BookingOperation{
+	PluginName + "-create": models.BookingCreate,
+	PluginName + "-get":    models.BookingGet,
+	PluginName + "-cancel": models.BookingCancel,
+}
+
+func main() {}
+
+func init() {
+	pluginCore = core.NewCarPluginCore()
+	pluginCore.RegisterBookingTransformer(transformers.NewRentlyBookingTransformer())
+}
+
+type registerer string
+
+var ModifierRegisterer = registerer(PluginName)
+
+func (r registerer) RegisterModifiers(f func(
+	name string,
+	modifierFactory func(map[string]interface{}) func(interface{}) (interface{}, error),
+	appliesToRequest bool,
+	appliesToResponse bool,
+)) {
+	for name, operation := range requestModifiers {
+		requestFactory, err := transformers.GetBookingRequestFactory(models.ProviderRently, operation)
+		if err != nil {
+			fmt.Printf("[PLUGIN: %s] %v\n", PluginName, err)
+			continue
+		}
+		f(name, requestFactory.BuildRequest(), true, false)
+	}
+
+	f(string(r), pluginCore.CreateBookingModifierFactory(models.ProviderRently), false, true)
+	fmt.Println(string(r), "registered!!!")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"hotels-common/client"
 	"hotels-common/core"
 	"hotels-common/models"
 	"net/http"
@@ -32,24 +33,28 @@ func (r registerer) RegisterClients(f func(
 }
 
 func (r registerer) registerClients(ctx context.Context, extra map[string]interface{}) (http.Handler, error) {
-	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	}), nil
+	config, _ := extra[PluginName].(map[string]interface{})
+	return client.NewProviderClient(ctx, models.ProviderTBO, config)
 }
 
 var HandlerRegisterer = handlerRegisterer(PluginName)
 
 func (hr handlerRegisterer) RegisterHandlers(f func(
 	name string,
-	handler func(context.Context, map[string]interface{}) (http.Handler, error),
+	handler func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error),
 )) {
 	f(string(hr), hr.registerHandlers)
 }
 
-func (hr handlerRegisterer) registerHandlers(ctx context.Context, extra map[string]interface{}) (http.Handler, error) {
-	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	}), nil
+// registerHandlers exposes the circuit breaker states of the provider at
+// "status_path" and passes every other request to the gateway.
+func (hr handlerRegisterer) registerHandlers(ctx context.Context, extra map[string]interface{}, next http.Handler) (http.Handler, error) {
+	config, _ := extra[PluginName].(map[string]interface{})
+	path, _ := config["status_path"].(string)
+	if path == "" {
+		path = "/__status/" + PluginName
+	}
+	return client.StatusHandler(path, next), nil
 }
 
 var ModifierRegisterer = registerer(PluginName)
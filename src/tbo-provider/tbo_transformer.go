@@ -16,8 +16,25 @@ func NewTBOTransformer() *TBOTransformer {
 	}
 }
 
+// PriceCheckStrategy issues the key of one hotel snapshot. Search results
+// get theirs in one batch from transformHotelArray.
 func (t *TBOTransformer) PriceCheckStrategy(data map[string]interface{}, config models.TransformationConfig) string {
-	return "TBO_PRICE_CHECK"
+	if config.PriceChecks == nil {
+		log.Printf("[TBO-TRANSFORMER] Price checks are not configured")
+		return ""
+	}
+	key, err := config.PriceChecks.Issue(config.Context(), string(models.ProviderTBO), data)
+	if err != nil {
+		log.Printf("[TBO-TRANSFORMER] Error issuing price check: %v", err)
+		return ""
+	}
+	return key
+}
+
+// UsesStore makes the modifier open a store for the rate snapshots behind
+// the price check keys.
+func (t *TBOTransformer) UsesStore() bool {
+	return true
 }
 
 func (t *TBOTransformer) GetProvider() models.Provider {
@@ -52,14 +69,17 @@ func (t *TBOTransformer) Transform(data map[string]interface{}, config models.Tr
 
 func (t *TBOTransformer) transformHotelArray(hotelArray []interface{}, config models.TransformationConfig) ([]models.BaseHotel, error) {
 	hotels := make([]models.BaseHotel, 0, len(hotelArray))
+	snapshots := make([]interface{}, 0, len(hotelArray))
 
 	for _, hotel := range hotelArray {
 		if hotelMap, ok := hotel.(map[string]interface{}); ok {
 			transformedHotel := t.transformSingleHotel(hotelMap, config)
 			hotels = append(hotels, transformedHotel)
+			snapshots = append(snapshots, hotelMap)
 		}
 	}
 
+	transformers.AssignPriceCheckKeys(config, models.ProviderTBO, hotels, snapshots)
 	return hotels, nil
 }
 
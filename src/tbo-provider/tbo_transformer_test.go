@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"hotels-common/models"
+	"hotels-common/pricecheck"
+	"redis"
+	"strings"
+	"testing"
+)
+
+func tboResponse() map[string]interface{} {
+	return map[string]interface{}{
+		"HotelResult": []interface{}{
+			map[string]interface{}{"HotelCode": "1000001", "Currency": "USD"},
+			map[string]interface{}{"HotelCode": "1000002", "Currency": "USD"},
+		},
+	}
+}
+
+func TestTransform_IssuesPriceChecksInBatch(t *testing.T) {
+	transformer := NewTBOTransformer()
+	store := redis.NewMemoryStore(0)
+	issuer, _ := pricecheck.NewIssuer(store, pricecheck.DefaultConfig())
+
+	resp, err := transformer.Transform(tboResponse(), models.TransformationConfig{Store: store, PriceChecks: issuer})
+	if err != nil || len(resp.Hotels) != 2 {
+		t.Fatalf("Expected two hotels, got %+v (%v)", resp.Hotels, err)
+	}
+	for i, hotel := range resp.Hotels {
+		payload, err := issuer.Resolve(context.Background(), string(models.ProviderTBO), hotel.PriceCheckKey)
+		if err != nil || !strings.Contains(string(payload), hotel.ProductID.(string)) {
+			t.Errorf("Expected hotel %d snapshot behind %q, got %s (%v)", i, hotel.PriceCheckKey, payload, err)
+		}
+	}
+}
+
+func TestTransform_IssuesPriceChecksWithoutStore(t *testing.T) {
+	transformer := NewTBOTransformer()
+	issuer, _ := pricecheck.NewIssuer(nil, pricecheck.DefaultConfig())
+
+	resp, err := transformer.Transform(tboResponse(), models.TransformationConfig{PriceChecks: issuer})
+	if err != nil || len(resp.Hotels) != 2 {
+		t.Fatalf("Expected two hotels, got %+v (%v)", resp.Hotels, err)
+	}
+	if _, err := issuer.Resolve(context.Background(), string(models.ProviderTBO), resp.Hotels[0].PriceCheckKey); err != nil {
+		t.Errorf("Expected the in-process price check to resolve, got %v", err)
+	}
+}
+
+func TestPriceCheckStrategy_IssuesKey(t *testing.T) {
+	transformer := NewTBOTransformer()
+	issuer, _ := pricecheck.NewIssuer(redis.NewMemoryStore(0), pricecheck.DefaultConfig())
+	hotel := map[string]interface{}{"HotelCode": "1000001"}
+
+	key := transformer.PriceCheckStrategy(hotel, models.TransformationConfig{PriceChecks: issuer})
+	if payload, err := issuer.Resolve(context.Background(), string(models.ProviderTBO), key); err != nil || !strings.Contains(string(payload), "1000001") {
+		t.Errorf("Expected the hotel snapshot behind %q, got %s (%v)", key, payload, err)
+	}
+}
@@ -0,0 +1,3 @@
+module tenancy
+
+go 1.25.3
@@ -0,0 +1,30 @@
+// Package tenancy carries the caller identity established by api-key-auth to
+// the handlers it wraps. The identity lives in the request context, where a
+// client cannot set it, unlike the tenant header forwarded to backends.
+//
+// Every plugin is built from the same package, so the context key is shared
+// by all of them at runtime.
+package tenancy
+
+import "context"
+
+// Identity is the caller of a request.
+type Identity struct {
+	Tenant string
+	// Public is set on paths that skip authentication; Tenant is empty.
+	Public bool
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying identity.
+func NewContext(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext returns the identity of ctx and whether the request went
+// through api-key-auth.
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+	return identity, ok
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plugin"
+	"testing"
+
+	"redis"
+)
+
+type registerer interface {
+	RegisterHandlers(func(
+		name string,
+		handler func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error),
+	))
+}
+
+// loadPlugins builds other plugins as the gateway does and returns their
+// handler factories by name, next to the one of this plugin.
+func loadPlugins(t *testing.T, names ...string) map[string]func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error) {
+	t.Helper()
+	dir := t.TempDir()
+	factories := map[string]func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error){
+		PluginName: HandlerRegisterer.registerHandlers,
+	}
+
+	for _, name := range names {
+		file := filepath.Join(dir, name+".so")
+		cmd := exec.Command("go", "build", "-buildmode=plugin", "-o", file, ".")
+		cmd.Dir = filepath.Join("..", name)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("error building %s: %v\n%s", name, err, out)
+		}
+
+		p, err := plugin.Open(file)
+		if err != nil {
+			t.Fatalf("error opening %s: %v", name, err)
+		}
+		symbol, err := p.Lookup("HandlerRegisterer")
+		if err != nil {
+			t.Fatalf("error looking up the registerer of %s: %v", name, err)
+		}
+		symbol.(registerer).RegisterHandlers(func(
+			name string,
+			handler func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error),
+		) {
+			factories[name] = handler
+		})
+	}
+	return factories
+}
+
+// serverConfig returns the http-server plugin configuration of the gateway
+// with every plugin on the in-memory store.
+func serverConfig(t *testing.T) map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "krakend.json"))
+	if err != nil {
+		t.Fatalf("error reading the gateway config: %v", err)
+	}
+	var config struct {
+		ExtraConfig map[string]map[string]interface{} `json:"extra_config"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("error parsing the gateway config: %v", err)
+	}
+
+	extra := config.ExtraConfig["plugin/http-server"]
+	for name, value := range extra {
+		if settings, ok := value.(map[string]interface{}); ok {
+			settings["store"] = redis.StoreMemory
+			delete(settings, "redis_password_env")
+			extra[name] = settings
+		}
+	}
+	return extra
+}
+
+func TestPluginChain_LimitsTheAuthenticatedTenant(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the plugins")
+	}
+
+	factories := loadPlugins(t, "api-key-auth")
+	extra := serverConfig(t)
+	extra[PluginName].(map[string]interface{})["default"] = map[string]interface{}{"limit": 1, "window": 60}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Wrap the router in the order of the http-server names, as KrakenD does.
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Header.Get("X-Tenant-ID")))
+	})
+	for _, name := range extra["name"].([]interface{}) {
+		factory, exists := factories[name.(string)]
+		if !exists {
+			continue
+		}
+		next, err := factory(ctx, extra, handler)
+		if err != nil {
+			t.Fatalf("error registering %s: %v", name, err)
+		}
+		handler = next
+	}
+
+	redisConfig, err := redis.LoadConfig(extra["api-key-auth"].(map[string]interface{}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	sum := sha256.Sum256([]byte("good"))
+	redis.GetMemoryStore(redisConfig).Set(ctx, redis.GetNamespace(redis.NamespaceAPIKey).Key(hex.EncodeToString(sum[:])),
+		`{"tenant_id":"acme","allowed_endpoints":["*"],"status":"active"}`)
+
+	serve := func(path, apiKey, tenant string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		if tenant != "" {
+			req.Header.Set("X-Tenant-ID", tenant)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/carros/matrix", "", "acme"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected a request without key to be rejected, got %d", rec.Code)
+	}
+	if rec := serve("/carros/matrix", "good", "other"); rec.Code != http.StatusOK || rec.Body.String() != "acme" {
+		t.Fatalf("expected the request forwarded as acme, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve("/carros/matrix", "good", "other"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected acme to be limited whatever tenant it claims, got %d", rec.Code)
+	}
+	if rec := serve("/__health", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected public paths to pass, got %d", rec.Code)
+	}
+}
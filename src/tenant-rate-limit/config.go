@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"redis"
+)
+
+// Rule limits the requests of a tenant to an endpoint. An empty tenant or
+// endpoint applies to every tenant or endpoint. Endpoints are exact paths or
+// a prefix ending in "/*".
+type Rule struct {
+	Tenant   string `json:"tenant"`
+	Endpoint string `json:"endpoint"`
+	Limit    int    `json:"limit"`
+	Window   int    `json:"window"`
+}
+
+func (r Rule) RateLimit() redis.RateLimit {
+	return redis.RateLimit{Limit: r.Limit, Window: time.Duration(r.Window) * time.Second}
+}
+
+// specificity ranks tenant and endpoint rules over tenant only rules, over
+// endpoint only rules, over the default.
+func (r Rule) specificity() int {
+	score := 0
+	if r.Tenant != "" {
+		score += 2
+	}
+	if r.Endpoint != "" {
+		score++
+	}
+	return score
+}
+
+type Config struct {
+	// Default applies to tenants without a matching rule. A zero limit
+	// leaves them unlimited.
+	Default Rule   `json:"default"`
+	Rules   []Rule `json:"rules"`
+}
+
+func DefaultConfig() *Config {
+	return &Config{
+		Default: Rule{Limit: 600, Window: 60},
+	}
+}
+
+func LoadConfig(extra map[string]interface{}) (*Config, error) {
+	config := DefaultConfig()
+
+	configData, err := json.Marshal(extra)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling config: %v", err)
+	}
+
+	if err := json.Unmarshal(configData, config); err != nil {
+		return nil, fmt.Errorf("error unmarshaling config: %v", err)
+	}
+
+	config.Default.Tenant, config.Default.Endpoint = "", ""
+
+	for i, rule := range append([]Rule{config.Default}, config.Rules...) {
+		if rule.Limit < 0 || (rule.Limit > 0 && rule.Window <= 0) {
+			return nil, fmt.Errorf("rule %d: limit and window must be positive", i)
+		}
+		if rule.Endpoint != "" && !strings.HasPrefix(rule.Endpoint, "/") {
+			return nil, fmt.Errorf("rule %d: endpoint %q must start with /", i, rule.Endpoint)
+		}
+	}
+
+	return config, nil
+}
+
+// Match returns the most specific rule for the request. Among equally
+// specific rules the first one configured wins.
+func (c *Config) Match(tenant, requestPath string) Rule {
+	match, best := c.Default, -1
+	for _, rule := range c.Rules {
+		if rule.Tenant != "" && rule.Tenant != tenant {
+			continue
+		}
+		if rule.Endpoint != "" && !matchPath(rule.Endpoint, requestPath) {
+			continue
+		}
+		if score := rule.specificity(); score > best {
+			match, best = rule, score
+		}
+	}
+	return match
+}
+
+func matchPath(pattern, requestPath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return requestPath == prefix || strings.HasPrefix(requestPath, prefix+"/")
+	}
+	return pattern == requestPath
+}
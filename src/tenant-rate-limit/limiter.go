@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"redis"
+	"tenancy"
+)
+
+const counterTimeout = 500 * time.Millisecond
+
+// Limiter enforces the request quota of each tenant. Counters live in the
+// store so every replica shares them. While the store is unavailable
+// requests are let through: losing the limits is better than losing the
+// gateway.
+type Limiter struct {
+	config *Config
+	now    func() time.Time
+	store  *redis.LazyStore
+}
+
+func NewLimiter(config *Config, store *redis.LazyStore) *Limiter {
+	return &Limiter{
+		config: config,
+		now:    time.Now,
+		store:  store,
+	}
+}
+
+func (l *Limiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// The tenant header could come from the client when api-key-auth
+		// did not run first; only the identity it sets is trusted.
+		identity, ok := tenancy.FromContext(req.Context())
+		if !ok {
+			log.Printf("[RATE-LIMIT] Rejected unauthenticated request to %s, api-key-auth must run first", req.URL.Path)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+		if identity.Public {
+			next.ServeHTTP(w, req)
+			return
+		}
+		tenant := identity.Tenant
+
+		rule := l.config.Match(tenant, req.URL.Path)
+		if rule.Limit == 0 {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		result, err := l.count(req.Context(), tenant, rule)
+		if err != nil {
+			log.Printf("[RATE-LIMIT] Not limiting tenant %s: %v", tenant, err)
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		writeHeaders(w.Header(), result)
+		if !result.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			log.Printf("[RATE-LIMIT] Tenant %s over %d requests per %ds on %s", tenant, rule.Limit, rule.Window, req.URL.Path)
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// count adds the request to the tenant counter of the rule. Endpoint rules
+// have their own counter; the others share one per tenant.
+func (l *Limiter) count(ctx context.Context, tenant string, rule Rule) (redis.RateLimitResult, error) {
+	store, err := l.store.Get()
+	if err != nil {
+		return redis.RateLimitResult{}, err
+	}
+
+	scope := rule.Endpoint
+	if scope == "" {
+		scope = "*"
+	}
+	key := redis.GetNamespace(redis.NamespaceRateLimit).Key(tenant, scope)
+
+	ctx, cancel := context.WithTimeout(ctx, counterTimeout)
+	defer cancel()
+	return redis.SlidingWindow(ctx, store, key, rule.RateLimit(), l.now())
+}
+
+func writeHeaders(h http.Header, result redis.RateLimitResult) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+}
+
+// seconds rounds up so clients never retry too early.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"redis"
+	"tenancy"
+)
+
+func newTestLimiter(t *testing.T, rules ...Rule) *Limiter {
+	t.Helper()
+	config, err := LoadConfig(map[string]interface{}{
+		"default": map[string]interface{}{"limit": 2, "window": 60},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	config.Rules = rules
+
+	l := NewLimiter(config, redis.NewLazyStore("rate limit", redis.NewMemoryStore(0), nil))
+	now := time.Unix(1_700_000_040, 0)
+	l.now = func() time.Time { return now }
+	return l
+}
+
+// serve sends a request authenticated as tenant, or to a public path when
+// tenant is empty.
+func serve(l *Limiter, tenant, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	identity := tenancy.Identity{Tenant: tenant, Public: tenant == ""}
+	return serveRequest(l, req.WithContext(tenancy.NewContext(req.Context(), identity)))
+}
+
+func serveRequest(l *Limiter, req *http.Request) *httptest.ResponseRecorder {
+	handler := l.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLimiter_RejectsOverQuota(t *testing.T) {
+	l := newTestLimiter(t)
+
+	for i := 0; i < 2; i++ {
+		if rec := serve(l, "acme", "/carros/matrix"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, rec.Code)
+		}
+	}
+
+	rec := serve(l, "acme", "/carros/matrix")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" || rec.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("expected Retry-After and no remaining requests, got %v", rec.Header())
+	}
+	if limit := rec.Header().Get("X-RateLimit-Limit"); limit != "2" {
+		t.Errorf("expected limit 2, got %s", limit)
+	}
+
+	if rec := serve(l, "other", "/carros/matrix"); rec.Code != http.StatusOK {
+		t.Errorf("expected other tenants to keep their quota, got %d", rec.Code)
+	}
+}
+
+func TestLimiter_TenantAndEndpointRules(t *testing.T) {
+	l := newTestLimiter(t,
+		Rule{Tenant: "acme", Limit: 5, Window: 60},
+		Rule{Tenant: "acme", Endpoint: "/products/hotels/*", Limit: 1, Window: 60},
+	)
+
+	if rule := l.config.Match("acme", "/products/hotels/availability"); rule.Limit != 1 {
+		t.Errorf("expected the tenant endpoint rule, got %+v", rule)
+	}
+	if rule := l.config.Match("acme", "/carros/matrix"); rule.Limit != 5 {
+		t.Errorf("expected the tenant rule, got %+v", rule)
+	}
+	if rule := l.config.Match("other", "/products/hotels/availability"); rule.Limit != 2 {
+		t.Errorf("expected the default rule, got %+v", rule)
+	}
+
+	serve(l, "acme", "/products/hotels/availability")
+	if rec := serve(l, "acme", "/products/hotels/availability"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("expected endpoint quota to be enforced, got %d", rec.Code)
+	}
+	if rec := serve(l, "acme", "/carros/matrix"); rec.Code != http.StatusOK {
+		t.Errorf("expected endpoint rule to keep its own counter, got %d", rec.Code)
+	}
+}
+
+func TestLimiter_FailsOpen(t *testing.T) {
+	config := DefaultConfig()
+	l := NewLimiter(config, redis.NewLazyStore("rate limit", nil, func() (redis.Store, error) {
+		return nil, errors.New("connection refused")
+	}))
+
+	if rec := serve(l, "acme", "/carros/matrix"); rec.Code != http.StatusOK {
+		t.Errorf("expected requests to pass while the store is down, got %d", rec.Code)
+	}
+	if rec := serve(l, "", "/__health"); rec.Code != http.StatusOK {
+		t.Errorf("expected requests without tenant to pass, got %d", rec.Code)
+	}
+}
+
+func TestLimiter_RejectsUnauthenticatedRequests(t *testing.T) {
+	l := newTestLimiter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/carros/matrix", nil)
+	req.Header.Set("X-Tenant-ID", "spoofed")
+	if rec := serveRequest(l, req); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected a request api-key-auth did not see to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestLoadConfig_InvalidRule(t *testing.T) {
+	_, err := LoadConfig(map[string]interface{}{
+		"rules": []interface{}{map[string]interface{}{"tenant": "acme", "limit": 10}},
+	})
+	if err == nil {
+		t.Error("expected a rule without window to be rejected")
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"redis"
+)
+
+const PluginName = "tenant-rate-limit"
+
+func main() {}
+
+type handlerRegisterer string
+
+var HandlerRegisterer = handlerRegisterer(PluginName)
+
+func (hr handlerRegisterer) RegisterHandlers(f func(
+	name string,
+	handler func(context.Context, map[string]interface{}, http.Handler) (http.Handler, error),
+)) {
+	f(string(hr), hr.registerHandlers)
+}
+
+// registerHandlers wraps the gateway router. It counts by the tenant
+// api-key-auth authenticated, so api-key-auth must come after it in the
+// http-server names and wrap it.
+func (hr handlerRegisterer) registerHandlers(ctx context.Context, extra map[string]interface{}, next http.Handler) (http.Handler, error) {
+	cfg, _ := extra[PluginName].(map[string]interface{})
+
+	config, err := LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading %s config: %v", PluginName, err)
+	}
+
+	redisConfig, err := redis.LoadConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error loading Redis config: %v", err)
+	}
+
+	store := redis.NewLazyStore("rate limit", nil, redis.OpenUntilDone(ctx, redisConfig))
+	if _, err := store.Get(); err != nil {
+		log.Printf("[RATE-LIMIT] Redis unavailable at start: %v", err)
+	}
+
+	log.Printf("[RATE-LIMIT] Limiting tenants with %d rules", len(config.Rules))
+	return NewLimiter(config, store).Handler(next), nil
+}
@@ -0,0 +1,7 @@
+module transform-rently
+
+go 1.25.3
+
+require cars-common v0.0.0
+
+replace cars-common => ../cars-common
@@ -5,13 +5,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"strings"
+
+	"cars-common/adapters"
+	"cars-common/models"
+	"cars-common/rendering"
+	"cars-common/transformers"
 )
 
 var PluginName = "transform-rently"
 
 var ModifierRegisterer = registerer(PluginName)
 
+var (
+	rentlyTransformer = transformers.NewRentlyTransformer()
+	extractor         = adapters.NewResponseExtractor()
+)
+
 type registerer string
 
 func init() {
@@ -43,9 +52,21 @@ func (r registerer) RegisterModifiers(f func(
 func (r registerer) modifierFactory(config map[string]interface{}) func(interface{}) (interface{}, error) {
 	fmt.Printf("[PLUGIN: %s] Configurando plugin\n", PluginName)
 
+	// El perfil por defecto mantiene las claves en español para los consumidores existentes
+	defaultProfile, _ := config["output_profile"].(string)
+	if defaultProfile == "" {
+		defaultProfile = rendering.ProfileV1ES
+	}
+	renderer := rendering.NewRenderer(defaultProfile)
+
 	return func(input interface{}) (interface{}, error) {
 		fmt.Println("[PLUGIN] Ejecutando transformación de respuesta")
 
+		profile := defaultProfile
+		if request, ok := extractor.ExtractRequest(input); ok {
+			profile = renderer.Select(request.Headers())
+		}
+
 		resp, ok := input.(ResponseWrapper)
 		if !ok {
 			fmt.Println("[PLUGIN] No es un ResponseWrapper válido")
@@ -67,7 +88,7 @@ func (r registerer) modifierFactory(config map[string]interface{}) func(interfac
 		} else {
 			// Reader es nil, intentar usar Data() en su lugar
 			fmt.Println("[PLUGIN] Reader es nil, usando Data()")
-			return transformUsingData(resp), nil
+			return transformUsingData(resp, renderer, profile), nil
 		}
 
 		// Si no hay cuerpo, devolver la respuesta original
@@ -79,7 +100,7 @@ func (r registerer) modifierFactory(config map[string]interface{}) func(interfac
 		fmt.Printf("[PLUGIN] Longitud del cuerpo: %d bytes\n", len(body))
 
 		// Intentar transformar el cuerpo JSON
-		modifiedBody, transformErr := transformBody(body)
+		modifiedBody, transformErr := transformBody(body, renderer, profile)
 		if transformErr != nil {
 			fmt.Printf("[PLUGIN] Error transformando cuerpo: %v\n", transformErr)
 			return input, nil
@@ -99,13 +120,13 @@ func (r registerer) modifierFactory(config map[string]interface{}) func(interfac
 }
 
 // transformBody transforma el cuerpo JSON
-func transformBody(body []byte) ([]byte, error) {
+func transformBody(body []byte, renderer *rendering.Renderer, profile string) ([]byte, error) {
 	// Primero intentar parsear como objeto con clave "collection"
 	var responseObj map[string]interface{}
 	if err := json.Unmarshal(body, &responseObj); err == nil {
 		if collection, exists := responseObj["collection"]; exists {
 			if cars, ok := collection.([]interface{}); ok {
-				completeResponse := buildCompleteResponse(cars)
+				completeResponse := buildCompleteResponse(cars, renderer, profile)
 				return json.Marshal(completeResponse)
 			}
 		}
@@ -118,7 +139,7 @@ func transformBody(body []byte) ([]byte, error) {
 		for _, car := range originalData {
 			cars = append(cars, car)
 		}
-		completeResponse := buildCompleteResponse(cars)
+		completeResponse := buildCompleteResponse(cars, renderer, profile)
 		return json.Marshal(completeResponse)
 	}
 
@@ -126,95 +147,21 @@ func transformBody(body []byte) ([]byte, error) {
 }
 
 // buildCompleteResponse construye la respuesta completa con pickup, dropoff y results
-func buildCompleteResponse(collection []interface{}) map[string]interface{} {
-	// Extraer información de pickup y dropoff del primer vehículo (si existe)
-	var pickup, dropoff map[string]interface{}
-
-	if len(collection) > 0 {
-		if firstCar, ok := collection[0].(map[string]interface{}); ok {
-			pickup = extractLocationInfo(firstCar, "deliveryPlace", "fromDate")
-			dropoff = extractLocationInfo(firstCar, "returnPlace", "toDate")
-		}
-	}
-
-	// Transformar la colección de vehículos
-	results := transformCollection(collection)
-
-	return map[string]interface{}{
-		"pickup":  pickup,
-		"dropoff": dropoff,
-		"results": results,
-	}
-}
-
-// extractLocationInfo extrae información de ubicación y fecha con todos los detalles
-func extractLocationInfo(car map[string]interface{}, placeKey, dateKey string) map[string]interface{} {
-	locationInfo := make(map[string]interface{})
-
-	// Extraer información del lugar (deliveryPlace o returnPlace)
-	if place, exists := car[placeKey]; exists {
-		if placeMap, ok := place.(map[string]interface{}); ok {
-			// Información básica de ubicación
-			if iata, exists := placeMap["iata"].(string); exists && iata != "" {
-				locationInfo["location"] = iata
-				locationInfo["iata"] = iata
-			}
-
-			// Información detallada del lugar
-			locationInfo["id"] = getFloat(placeMap, "id")
-			locationInfo["email"] = getString(placeMap, "email")
-			locationInfo["city"] = getString(placeMap, "city")
-			locationInfo["country"] = getString(placeMap, "country")
-			locationInfo["address2"] = getString(placeMap, "address2")
-			locationInfo["zipCode"] = getString(placeMap, "zipCode")
-			locationInfo["type"] = getString(placeMap, "type")
-			locationInfo["serviceType"] = getString(placeMap, "serviceType")
-			locationInfo["pickupInstructions"] = getString(placeMap, "pickupInstructions")
-
-			// Coordenadas
-			locationInfo["latitude"] = getFloat(placeMap, "latitude")
-			locationInfo["longitude"] = getFloat(placeMap, "longitude")
-		}
-	}
-
-	// Extraer fecha y hora
-	if dateStr, exists := car[dateKey].(string); exists {
-		// Formatear fecha: "2025-11-29T11:00:00Z" -> "2025-11-29"
-		dateParts := strings.Split(dateStr, "T")
-		if len(dateParts) > 0 {
-			locationInfo["date"] = dateParts[0]
-		}
-
-		// Formatear hora: "2025-11-29T11:00:00Z" -> "1100"
-		if len(dateParts) > 1 {
-			timeParts := strings.Split(dateParts[1], ":")
-			if len(timeParts) >= 2 {
-				locationInfo["time"] = timeParts[0] + timeParts[1]
-			}
-		}
-	}
-
-	// Si no se pudo extraer información esencial, usar valores por defecto
-	if locationInfo["location"] == nil {
-		locationInfo["location"] = "MIA"
-		locationInfo["iata"] = "MIA"
-	}
-	if locationInfo["date"] == nil {
-		if placeKey == "deliveryPlace" {
-			locationInfo["date"] = "2025-11-21"
-		} else {
-			locationInfo["date"] = "2025-11-26"
-		}
-	}
-	if locationInfo["time"] == nil {
-		locationInfo["time"] = "1200"
+// a partir del modelo canónico, en el perfil de salida solicitado
+func buildCompleteResponse(collection []interface{}, renderer *rendering.Renderer, profile string) map[string]interface{} {
+	response, err := rentlyTransformer.Transform(
+		map[string]interface{}{"collection": collection},
+		models.TransformationConfig{Provider: models.ProviderRently},
+	)
+	if err != nil {
+		fmt.Printf("[PLUGIN] Error transformando colección: %v\n", err)
 	}
 
-	return locationInfo
+	return renderer.RenderCars(response, profile)
 }
 
 // transformUsingData transforma usando el método Data() cuando Io() es nil
-func transformUsingData(resp ResponseWrapper) interface{} {
+func transformUsingData(resp ResponseWrapper, renderer *rendering.Renderer, profile string) interface{} {
 	data := resp.Data()
 	if data == nil {
 		return resp
@@ -225,7 +172,7 @@ func transformUsingData(resp ResponseWrapper) interface{} {
 	// Intentar extraer datos de la respuesta
 	if collection, exists := data["collection"]; exists {
 		if cars, ok := collection.([]interface{}); ok {
-			completeResponse := buildCompleteResponse(cars)
+			completeResponse := buildCompleteResponse(cars, renderer, profile)
 
 			// Convertir a JSON
 			modifiedBody, err := json.Marshal(completeResponse)
@@ -246,92 +193,6 @@ func transformUsingData(resp ResponseWrapper) interface{} {
 	return resp
 }
 
-// transformCollection transforma la colección de vehículos
-func transformCollection(collection []interface{}) []map[string]interface{} {
-	var simplified []map[string]interface{}
-
-	for _, item := range collection {
-		if car, ok := item.(map[string]interface{}); ok {
-			simplifiedCar := extractSimplifiedCarInfo(car)
-			simplified = append(simplified, simplifiedCar)
-		}
-	}
-
-	return simplified
-}
-
-// extractSimplifiedCarInfo extrae la información simplificada del auto
-func extractSimplifiedCarInfo(car map[string]interface{}) map[string]interface{} {
-	simplified := map[string]interface{}{
-		"nombre":               getString(car, "model", "name"),
-		"marca":                getString(car, "model", "brand"),
-		"tipo":                 getString(car, "category", "name"),
-		"tarifa":               getFloat(car, "price"),
-		"tarifa_con_impuestos": getFloat(car, "customerPrice"),
-		"tarifa_sin_impuestos": calculatePriceWithoutTax(car),
-		"proveedor":            getString(car, "supplier", "name"),
-		"franquicia":           getFloat(car, "franchise"),
-		"dias_totales":         getDays(car, "totalDays"),
-	}
-
-	fmt.Printf("[PLUGIN] Auto transformado: %s - %.2f USD\n",
-		getString(car, "model", "name"), getFloat(car, "customerPrice"))
-
-	return simplified
-}
-
-// calculatePriceWithoutTax calcula el precio base sin impuestos
-func calculatePriceWithoutTax(car map[string]interface{}) float64 {
-	var basePrice float64
-
-	if priceItems, ok := car["priceItems"].([]interface{}); ok {
-		for _, item := range priceItems {
-			if priceItem, ok := item.(map[string]interface{}); ok {
-				if itemType, ok := priceItem["type"].(string); ok && itemType == "Booking" {
-					if price, ok := priceItem["price"].(float64); ok {
-						basePrice += price
-					}
-				}
-			}
-		}
-	}
-
-	return basePrice
-}
-
-// Helper functions para acceder de forma segura a los datos anidados
-func getString(data map[string]interface{}, keys ...string) string {
-	current := data
-	for i, key := range keys {
-		if i == len(keys)-1 {
-			if val, ok := current[key].(string); ok {
-				return val
-			}
-			return ""
-		}
-		if next, ok := current[key].(map[string]interface{}); ok {
-			current = next
-		} else {
-			return ""
-		}
-	}
-	return ""
-}
-
-func getFloat(data map[string]interface{}, key string) float64 {
-	if val, ok := data[key].(float64); ok {
-		return val
-	}
-	return 0
-}
-
-func getDays(data map[string]interface{}, key string) string {
-	if val, ok := data[key].(string); ok {
-		return val
-	}
-	return ""
-}
-
 func parseJSON(data []byte) interface{} {
 	var result interface{}
 	if err := json.Unmarshal(data, &result); err != nil {